@@ -4,6 +4,8 @@
 
 package actor
 
+import "time"
+
 /*
 Go-actor的目标是让开发者能够更加容易地使用actor模型。
 The goal of go-actor is to make it easier for developers to use the actor model.
@@ -54,14 +56,16 @@ message should be a ProtoBuf message, ProtoBuf is used in actor.Remote serializi
 //      A function or a closure to create an actor instance.
 //   #2 可以为空的actor启动参数，这个参数会在actor启动的时候，通过StartUp方法传递给actor。
 //      A nullable actor startup argument, that will pass to the actor via StartUp method.
+//   #3 可选的启动选项。
+//      Optional spawn options.
 //
 // Returns:
 //   #1 如果启动成功，返回本地actor的引用。
 //      A reference of local actor, if it starts up successfully.
 //   #2 如果启动失败，返回error。
 //      Error message if starts failed.
-func Spawn(fn func() Actor, arg interface{}) (*LocalRef, error) {
-	return defaultSys.Spawn(fn, arg)
+func Spawn(fn func() Actor, arg interface{}, opts ...SpawnOption) (*LocalRef, error) {
+	return defaultSys.Spawn(fn, arg, opts...)
 }
 
 
@@ -78,14 +82,16 @@ func Spawn(fn func() Actor, arg interface{}) (*LocalRef, error) {
 //      A name that tries to register to local system.
 //   #3 可以为空的actor启动参数，这个参数会在actor启动的时候，通过StartUp方法传递给actor。
 //      A nullable actor startup argument, that will pass to the actor via StartUp method.
+//   #4 可选的启动选项，例如WithDurableMailbox。
+//      Optional spawn options, such as WithDurableMailbox.
 //
 // Returns:
 //   #1 如果启动成功，返回本地actor的引用。
 //      A reference of local actor, if it starts up successfully.
 //   #2 如果启动失败，返回error。
 //      Error message if starts failed.
-func SpawnWithName(fn func() Actor, name string, arg interface{}) (*LocalRef, error) {
-	return defaultSys.SpawnWithName(fn, name, arg)
+func SpawnWithName(fn func() Actor, name string, arg interface{}, opts ...SpawnOption) (*LocalRef, error) {
+	return defaultSys.SpawnWithName(fn, name, arg, opts...)
 }
 
 
@@ -115,6 +121,11 @@ type NodeConfig struct {
 	ListenNetwork Network
 	ListenAddress string
 	AuthToken     string
+	// Heartbeat settings, only used by Init.
+	// A remote node is considered down if no packet has been received from it
+	// during HeartbeatInterval * HeartbeatMissed.
+	HeartbeatInterval time.Duration
+	HeartbeatMissed   int
 }
 
 const (
@@ -133,6 +144,8 @@ func (m *remoteManager) Init(config NodeConfig) error {
 		config.ListenAddress = NodeDefaultAddress
 	}
 	m.conn.remote = m
+	m.conn.heartbeatInterval = config.HeartbeatInterval
+	m.conn.heartbeatMissed = config.HeartbeatMissed
 	m.nodeId = config.Id
 	if err := m.conn.init(config.ListenNetwork, config.ListenAddress); err != nil {
 		return err
@@ -144,6 +157,7 @@ func (m *remoteManager) Init(config NodeConfig) error {
 
 func (m *remoteManager) Close() {
 	m.ready = false
+	m.reliable.disable()
 	m.nodeId = 0
 	m.conn.close()
 }
@@ -175,6 +189,14 @@ func (m *remoteManager) GetConn(nodeId uint32) (*RemoteConn, error) {
 }
 
 
+// Register a function to be notified when a remote node is down. A remote node is
+// considered down, if its connection has been broken, or it has missed heartbeats.
+// All pending requests to the node will fail with ErrRemoteNodeDown immediately.
+// Do not block this function.
+func (m *remoteManager) OnNodeDown(fn func(nodeId uint32)) {
+	m.conn.onNodeDown(fn)
+}
+
 //
 // DEVELOPER TO IMPLEMENT
 //
@@ -212,6 +234,8 @@ type Actor interface {
 	// being call Shutdown, during this process, the Shutdown method of an actor will be
 	// called.
 	// IMPORTANT: PLEASE SAVE ALL IMPORTANT DATA OF AN ACTOR WHEN IT IS CALLED SHUTDOWN.
+	// Shutdown will not be called if an actor exits by panic, implement PersistentActor
+	// interface to persist state as events if it should not be lost.
 	Shutdown()
 }
 
@@ -278,25 +302,21 @@ func (m Id) Name() string {
 	return m.name
 }
 
+// Key of an actor in maps, name is excluded, since it is cleared once the actor
+// is shutting down.
+type idKey struct {
+	node, id uint32
+}
+
+func (m Id) key() idKey {
+	return idKey{node: m.node, id: m.id}
+}
+
 // todo: to watch an id, notify when the actor of this id has been shutdown.
 //func Watch(id Id) {
 //
 //}
 
-// todo: to batch send a message to a basket of reference, no matter LocalRef or RemoteRef
-// todo: group send
-//func BatchSend(sender Ref, targets []Ref, msg interface{}) (errors map[Id]error) {
-//
-//}
-//type GroupRef interface {
-//	AddRef()
-//	AddName()
-//	DelRef()
-//	DelName()
-//	Send()
-//	Ask()
-//}
-
 // todo: performance monitoring system
 // running actors
 // timeout session
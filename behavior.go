@@ -0,0 +1,48 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+// Functions to handle messages instead of HandleSend and HandleAsk of the actor,
+// see LocalRef.Become. Nil Send or Ask falls back to the method of the actor.
+type Behavior struct {
+	Send func(sender Ref, message interface{})
+	Ask  func(sender Ref, ask interface{}) (answer interface{}, err error)
+}
+
+// Push behavior to the behavior stack, the behavior on top handles the next
+// messages. It should only be called inside the actor.
+func (m *LocalRef) Become(b Behavior) {
+	m.behaviors = append(m.behaviors, b)
+}
+
+// Pop behavior from the behavior stack, returns false if the stack is empty, and
+// methods of the actor handle messages once the stack is empty. It should only be
+// called inside the actor.
+func (m *LocalRef) Unbecome() bool {
+	n := len(m.behaviors)
+	if n == 0 {
+		return false
+	}
+	m.behaviors[n-1] = Behavior{}
+	m.behaviors = m.behaviors[:n-1]
+	return true
+}
+
+func (m *LocalRef) handleSend(sender Ref, message interface{}) {
+	if n := len(m.behaviors); n > 0 && m.behaviors[n-1].Send != nil {
+		m.behaviors[n-1].Send(sender, message)
+		return
+	}
+	m.actor.HandleSend(sender, message)
+}
+
+func (m *LocalRef) handleAsk(sender Ref, ask interface{}) (answer interface{}, err error) {
+	if n := len(m.behaviors); n > 0 && m.behaviors[n-1].Ask != nil {
+		return m.behaviors[n-1].Ask(sender, ask)
+	}
+	if m.ask == nil {
+		return nil, ErrActorCannotAsk
+	}
+	return m.ask.HandleAsk(sender, ask)
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+//
+// Content codec
+// Encode and decode a value to DataContentType, it is used to persist values.
+// Supported types: proto.Message, bool, []byte, string, int, int8, int16, int32,
+// int64, uint, uint8, uint16, uint32, uint64, float32 and float64.
+//
+
+func encodeContent(v interface{}) (*DataContentType, error) {
+	c := &DataContentType{}
+	switch val := v.(type) {
+	case proto.Message:
+		a, err := ptypes.MarshalAny(val)
+		if err != nil {
+			return nil, err
+		}
+		c.Type = DataType_ProtoBuf
+		c.Content = &DataContentType_Proto{Proto: a}
+	case bool:
+		c.Type = DataType_Bool
+		c.Content = &DataContentType_B{B: val}
+	case []byte:
+		c.Type = DataType_Bytes
+		c.Content = &DataContentType_Bs{Bs: val}
+	case string:
+		c.Type = DataType_String
+		c.Content = &DataContentType_Str{Str: val}
+	case int:
+		c.Type = DataType_Int
+		c.Content = &DataContentType_I64{I64: int64(val)}
+	case int8:
+		c.Type = DataType_Int8
+		c.Content = &DataContentType_I64{I64: int64(val)}
+	case int16:
+		c.Type = DataType_Int16
+		c.Content = &DataContentType_I64{I64: int64(val)}
+	case int32:
+		c.Type = DataType_Int32
+		c.Content = &DataContentType_I64{I64: int64(val)}
+	case int64:
+		c.Type = DataType_Int64
+		c.Content = &DataContentType_I64{I64: val}
+	case uint:
+		c.Type = DataType_UInt
+		c.Content = &DataContentType_U64{U64: uint64(val)}
+	case uint8:
+		c.Type = DataType_UInt8
+		c.Content = &DataContentType_U64{U64: uint64(val)}
+	case uint16:
+		c.Type = DataType_UInt16
+		c.Content = &DataContentType_U64{U64: uint64(val)}
+	case uint32:
+		c.Type = DataType_UInt32
+		c.Content = &DataContentType_U64{U64: uint64(val)}
+	case uint64:
+		c.Type = DataType_UInt64
+		c.Content = &DataContentType_U64{U64: val}
+	case float32:
+		c.Type = DataType_Float32
+		c.Content = &DataContentType_F64{F64: float64(val)}
+	case float64:
+		c.Type = DataType_Float64
+		c.Content = &DataContentType_F64{F64: val}
+	default:
+		return nil, ErrContentType
+	}
+	return c, nil
+}
+
+func decodeContent(c *DataContentType) (interface{}, error) {
+	switch c.Type {
+	case DataType_ProtoBuf:
+		a := c.GetProto()
+		if a == nil {
+			return nil, ErrContentType
+		}
+		pb, err := ptypes.Empty(a)
+		if err != nil {
+			return nil, err
+		}
+		if err := ptypes.UnmarshalAny(a, pb); err != nil {
+			return nil, err
+		}
+		return pb, nil
+	case DataType_Bool:
+		return c.GetB(), nil
+	case DataType_Bytes:
+		return c.GetBs(), nil
+	case DataType_String:
+		return c.GetStr(), nil
+	case DataType_Int:
+		return int(c.GetI64()), nil
+	case DataType_Int8:
+		return int8(c.GetI64()), nil
+	case DataType_Int16:
+		return int16(c.GetI64()), nil
+	case DataType_Int32:
+		return int32(c.GetI64()), nil
+	case DataType_Int64:
+		return c.GetI64(), nil
+	case DataType_UInt:
+		return uint(c.GetU64()), nil
+	case DataType_UInt8:
+		return uint8(c.GetU64()), nil
+	case DataType_UInt16:
+		return uint16(c.GetU64()), nil
+	case DataType_UInt32:
+		return uint32(c.GetU64()), nil
+	case DataType_UInt64:
+		return c.GetU64(), nil
+	case DataType_Float32:
+		return float32(c.GetF64()), nil
+	case DataType_Float64:
+		return c.GetF64(), nil
+	}
+	return nil, ErrContentType
+}
+
+func marshalContent(v interface{}) ([]byte, error) {
+	c, err := encodeContent(v)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(c)
+}
+
+func unmarshalContent(buf []byte) (interface{}, error) {
+	c := &DataContentType{}
+	if err := proto.Unmarshal(buf, c); err != nil {
+		return nil, err
+	}
+	return decodeContent(c)
+}
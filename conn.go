@@ -4,15 +4,18 @@
 package actor
 
 import (
+	"bufio"
 	"encoding/binary"
 	"errors"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
-	"github.com/golang/protobuf/ptypes/any"
 	"io"
 	"log"
 	"net"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,11 +34,20 @@ const (
 const (
 	readTimeout            = 0
 	readBufferSize         = 1024
+	writeBufferSize        = 64 * 1024
+	writeChannelSize       = 256
 	packetSizeLimit        = 1024 * 1024
 	packetHeaderSize       = 4 // Int
 	packetChannelSize      = 5
 	authTimeout            = 5
 	inMessageChannelLength = 10
+	sendAckBatchSize       = 128
+	sendAckInterval        = 50 * time.Millisecond
+)
+
+const (
+	NodeDefaultHeartbeatInterval = 1 * time.Second
+	NodeDefaultHeartbeatMissed   = 3
 )
 
 // Connection
@@ -49,6 +61,17 @@ type conn struct {
 	inMessageCh chan *inReply
 	outConn     map[uint32]*outNode
 	outConnLock sync.RWMutex
+	// batch acknowledged requests of nodes which have been down, resent after the
+	// nodes have been dialed again
+	unacked     map[uint32][]*ConnMessage
+	unackedLock sync.Mutex
+	inOrder     map[inOrderKey][]*inReply
+	inOrderLock sync.Mutex
+	// heartbeat
+	heartbeatInterval time.Duration
+	heartbeatMissed   int
+	nodeDownFn        []func(nodeId uint32)
+	nodeDownLock      sync.RWMutex
 }
 
 func (m *conn) init(nw Network, listen string) error {
@@ -63,7 +86,15 @@ func (m *conn) init(nw Network, listen string) error {
 	m.inAuth = ""
 	m.inConn = make(map[uint32]*inNode)
 	m.inMessageCh = make(chan *inReply, inMessageChannelLength)
+	m.inOrder = make(map[inOrderKey][]*inReply)
 	m.outConn = make(map[uint32]*outNode)
+	m.unacked = make(map[uint32][]*ConnMessage)
+	if m.heartbeatInterval <= 0 {
+		m.heartbeatInterval = NodeDefaultHeartbeatInterval
+	}
+	if m.heartbeatMissed <= 0 {
+		m.heartbeatMissed = NodeDefaultHeartbeatMissed
+	}
 	go m.inMessageHandler()
 	go m.inConnHandler()
 	return nil
@@ -76,262 +107,330 @@ func (m *conn) inMessageHandler() {
 		if !more {
 			break
 		}
-		if msg == nil || msg.inConn == nil || msg.inMessage == nil ||
+		if msg == nil || msg.nodeId == 0 || msg.inMessage == nil ||
 			msg.inMessage.Content == nil {
 			log.Println("actor.Remote handled incoming message error,", msg)
 			continue
 		}
-		// High effective parallel working mode
-		go func() {
-			replyMessage := &ConnMessage{}
-			switch msg.inMessage.Type {
-			case ControlType_CSendName:
-				{
-					// Validation
-					sendNameWrapper := msg.inMessage.GetSendName()
-					resp := &SendName_Response{
-						HasError: true,
-					}
-					replyMessage.Type = ControlType_CSendName
-					replyMessage.Content = &ConnMessage_SendName{
-						SendName: &SendName{
-							Data: &SendName_Resp{
-								Resp: resp,
-							},
-						},
-					}
-					if sendNameWrapper == nil {
-						log.Println("actor.Remote handled incoming message, empty send message error,", msg)
-						resp.ErrorMessage = "Empty message"
-						break
-					}
-					sendName := sendNameWrapper.GetReq()
-					if sendName == nil || sendName.SendData == nil {
-						log.Println("actor.Remote handled incoming message, empty send message request error,", msg)
-						resp.ErrorMessage = "Empty message request"
-						break
-					}
+		m.dispatchInMessage(msg)
+	}
+}
 
-					// Get local actor by name
-					localRef := m.remote.sys.locals.getName(sendName.ToName)
-					if localRef == nil {
-						resp.ErrorMessage = "Actor name not found"
-						break
-					}
+// Messages from the same sender to the same receiver are handled one by one in
+// order, messages of different sender-receiver pairs are handled in parallel.
+func (m *conn) dispatchInMessage(msg *inReply) {
+	key, ordered := inMessageOrderKey(msg)
+	if !ordered {
+		go m.handleInMessage(msg)
+		return
+	}
+	m.inOrderLock.Lock()
+	if queue, has := m.inOrder[key]; has {
+		m.inOrder[key] = append(queue, msg)
+		m.inOrderLock.Unlock()
+		return
+	}
+	m.inOrder[key] = []*inReply{}
+	m.inOrderLock.Unlock()
+	go func() {
+		for {
+			m.handleInMessage(msg)
+			m.inOrderLock.Lock()
+			queue := m.inOrder[key]
+			if len(queue) == 0 {
+				delete(m.inOrder, key)
+				m.inOrderLock.Unlock()
+				return
+			}
+			msg, m.inOrder[key] = queue[0], queue[1:]
+			m.inOrderLock.Unlock()
+		}
+	}()
+}
 
-					// Process send message
-					var (
-						sendFromRef *RemoteRef
-						sendMessage interface{}
-						sendError   error
-					)
-					if sendName.FromId != 0 {
-						sendFromRef = &RemoteRef{
-							id: Id{
-								node: msg.inConn.nodeId,
-								id:   sendName.FromId,
-								name: sendName.FromName,
-							},
-							node: nil, // todo
-						}
-					}
-					switch sendName.SendData.Type {
-					case DataType_ProtoBuf:
-						sendData := sendName.SendData.GetProto()
-						sendDataProto, err := ptypes.Empty(sendData)
-						if err != nil {
-							sendError = err
-							break
-						}
-						err = ptypes.UnmarshalAny(sendData, sendDataProto)
-						if err != nil {
-							sendError = err
-							break
-						}
-						sendMessage = sendDataProto
-					case DataType_String:
-						sendMessage = sendName.SendData.GetStr()
-					default:
-						sendError = errors.New("unsupported type") // todo
-						break
-					}
-					if sendError != nil {
-						resp.ErrorMessage = sendError.Error()
-						break
-					}
+type inOrderKey struct {
+	control  ControlType
+	nodeId   uint32
+	fromId   uint32
+	fromName string
+	toId     uint32
+	toName   string
+}
 
-					// Send local actor
-					sendError = localRef.Send(sendFromRef, sendMessage)
-					if sendError != nil {
-						resp.ErrorMessage = sendError.Error()
-					} else {
-						resp.HasError = false
-					}
-				}
-			case ControlType_CAskName:
-				{
-					// Validation
-					askNameWrapper := msg.inMessage.GetAskName()
-					resp := &AskName_Response{
-						HasError: true,
-						AnswerData: &DataContentType{},
-					}
-					replyMessage.Type = ControlType_CAskName
-					replyMessage.Content = &ConnMessage_AskName{
-						AskName: &AskName{
-							Data: &AskName_Resp{
-								Resp: resp,
-							},
-						},
-					}
-					if askNameWrapper == nil {
-						log.Println("actor.Remote handled incoming message, empty ask message error,", msg)
-						resp.ErrorMessage = "Empty message"
-						break
-					}
-					askName := askNameWrapper.GetReq()
-					if askName == nil || askName.AskData == nil || askName.AnswerData == nil {
-						log.Println("actor.Remote handled incoming message, empty ask message request error,", msg)
-						resp.ErrorMessage = "Empty message request"
-						break
-					}
+func inMessageOrderKey(msg *inReply) (key inOrderKey, ordered bool) {
+	key.nodeId = msg.nodeId
+	switch msg.inMessage.Type {
+	case ControlType_CSendName:
+		req := msg.inMessage.GetSendName().GetReq()
+		key.fromId, key.fromName, key.toName = req.GetFromId(), req.GetFromName(), req.GetToName()
+		key.toId = req.GetToId()
+		return key, true
+	case ControlType_CAskName:
+		req := msg.inMessage.GetAskName().GetReq()
+		key.fromId, key.fromName, key.toName = req.GetFromId(), req.GetFromName(), req.GetToName()
+		return key, true
+	case ControlType_CPublish:
+		// events of a topic are handled in order
+		key.control = ControlType_CPublish
+		key.toName = msg.inMessage.GetPublish().GetTopic()
+		return key, true
+	}
+	return key, false
+}
 
-					// Get local actor by name
-					localRef := m.remote.sys.locals.getName(askName.ToName)
-					if localRef == nil {
-						resp.ErrorMessage = "Actor name not found"
-						break
-					}
+func (m *conn) handleInMessage(msg *inReply) {
+	replyMessage := &ConnMessage{}
+	ackMode := AckMode_AckEach
+	switch msg.inMessage.Type {
+	case ControlType_CSendName:
+		{
+			// Validation
+			sendNameWrapper := msg.inMessage.GetSendName()
+			resp := &SendName_Response{
+				HasError: true,
+			}
+			replyMessage.Type = ControlType_CSendName
+			replyMessage.Content = &ConnMessage_SendName{
+				SendName: &SendName{
+					Data: &SendName_Resp{
+						Resp: resp,
+					},
+				},
+			}
+			if sendNameWrapper == nil {
+				log.Println("actor.Remote handled incoming message, empty send message error,", msg)
+				resp.ErrorMessage = "Empty message"
+				break
+			}
+			sendName := sendNameWrapper.GetReq()
+			if sendName == nil || sendName.SendData == nil {
+				log.Println("actor.Remote handled incoming message, empty send message request error,", msg)
+				resp.ErrorMessage = "Empty message request"
+				break
+			}
+			ackMode = sendName.AckMode
 
-					// Process ask message
-					var (
-						askFromRef    *RemoteRef
-						askMessage    interface{}
-						answerError   error
-					)
-					if askName.FromId != 0 {
-						askFromRef = &RemoteRef{
-							id: Id{
-								node: msg.inConn.nodeId,
-								id:   askName.FromId,
-								name: askName.FromName,
-							},
-							node: nil, // todo
-						}
-					}
-					// Ask
-					switch askName.AskData.Type {
-					case DataType_ProtoBuf:
-						askData := askName.AskData.GetProto()
-						askDataProto, err := ptypes.Empty(askData)
-						if err != nil {
-							answerError = err
-							break
-						}
-						err = ptypes.UnmarshalAny(askData, askDataProto)
-						if err != nil {
-							answerError = err
-							break
-						}
-						askMessage = askDataProto
-					case DataType_String:
-						askMessage = askName.AskData.GetStr()
-					default:
-						answerError = errors.New("unsupported type") // todo
-						break
-					}
-					if answerError != nil {
-						resp.ErrorMessage = answerError.Error()
-						break
-					}
-					// Answer
-					switch askName.AnswerData.Type {
-					case DataType_ProtoBuf:
-						var answerProto *any.Any
-						resp.AnswerData.Type = DataType_ProtoBuf
-						emptyAnswerProto := askName.AnswerData.GetProto()
-						answerInstance, err := ptypes.Empty(emptyAnswerProto)
-						if err != nil {
-							answerError = err
-							break
-						}
-						err = ptypes.UnmarshalAny(emptyAnswerProto, answerInstance)
-						if err != nil {
-							answerError = err
-							break
-						}
-						// Send local actor
-						answerError = localRef.Ask(askFromRef, askMessage, &answerInstance)
-						answerProto, err = ptypes.MarshalAny(answerInstance)
-						if err != nil {
-							answerError = err
-							break
-						}
-						resp.AnswerData.Content = &DataContentType_Proto{
-							Proto: answerProto,
-						}
-					case DataType_String:
-						answerString := ""
-						resp.AnswerData.Type = DataType_String
-						// Send local actor
-						answerError = localRef.Ask(askFromRef, askMessage, &answerString)
-						resp.AnswerData.Content = &DataContentType_Str{
-							Str: answerString,
-						}
-					default:
-						answerError = errors.New("unsupported type") // todo
-						break
-					}
-					// Error
-					if answerError != nil {
-						resp.ErrorMessage = answerError.Error()
-					} else {
-						resp.HasError = false
-					}
-				}
-			case ControlType_CGetName:
-				{
-					// Validation
-					getNameWrapper := msg.inMessage.GetGetName()
-					resp := &GetName_Response{
-						Has: false,
-					}
-					replyMessage.Type = ControlType_CGetName
-					replyMessage.Content = &ConnMessage_GetName{
-						GetName: &GetName{
-							Data: &GetName_Resp{
-								Resp: resp,
-							},
-						},
-					}
-					if getNameWrapper == nil {
-						log.Println("actor.Remote handled incoming message, empty get message error,", msg)
-						resp.ErrorMessage = "Empty message"
-						break
-					}
-					getName := getNameWrapper.GetReq()
-					if getName == nil || getName.Name == "" {
-						log.Println("actor.Remote handled incoming message, empty get message request error,", msg)
-						resp.ErrorMessage = "Empty message request"
-						break
-					}
+			// Get local actor by name
+			sendFromRef := m.remoteSender(msg.inConn, msg.nodeId, sendName.FromId, sendName.FromName)
+			var localRef *LocalRef
+			if sendName.ToId != 0 {
+				localRef = m.remote.sys.ById(sendName.ToId)
+			} else {
+				localRef = m.remote.sys.ByName(sendName.ToName)
+			}
+			if localRef == nil {
+				resp.ErrorMessage = "Actor name not found"
+				m.remoteDeadLetter(sendFromRef, sendName.ToName, sendName.SendData)
+				break
+			}
 
-					// Process get message
-					if lr := m.remote.sys.locals.getName(getName.Name); lr != nil {
-						resp.Has = true
-						resp.ActorId = lr.id.id
-					} else {
-						resp.ErrorMessage = "Actor name not found"
-					}
+			// Process send message
+			var (
+				sendMessage interface{}
+				sendError   error
+			)
+			switch sendName.SendData.Type {
+			case DataType_ProtoBuf:
+				sendData := sendName.SendData.GetProto()
+				sendDataProto, err := ptypes.Empty(sendData)
+				if err != nil {
+					sendError = err
+					break
+				}
+				err = ptypes.UnmarshalAny(sendData, sendDataProto)
+				if err != nil {
+					sendError = err
+					break
 				}
+				sendMessage = sendDataProto
+			case DataType_String:
+				sendMessage = sendName.SendData.GetStr()
 			default:
-				log.Println("actor.Remote handled incoming message type error,", msg)
+				sendError = errors.New("unsupported type") // todo
+				break
 			}
-			if err := msg.reply(replyMessage); err != nil {
-				log.Println("actor.Remote handled incoming message reply error,", err)
+			if sendError != nil {
+				resp.ErrorMessage = sendError.Error()
+				break
 			}
-		}()
+
+			sendMessage = m.envelope(msg.inConn, msg.nodeId, sendName.Envelope, sendMessage)
+
+			// Drop duplicated message, which has been delivered
+			dedup := sendName.Delivery == Delivery_ExactlyOnce && sendName.MessageId != ""
+			if dedup && !m.remote.reliable.dedup.mark(msg.nodeId, sendName.MessageId) {
+				log.Println("actor.Remote handled incoming message, duplicated message,", sendName.MessageId)
+				resp.HasError = false
+				break
+			}
+
+			// Send local actor
+			sendError = localRef.Send(sendFromRef, sendMessage)
+			if sendError != nil {
+				resp.ErrorMessage = sendError.Error()
+				if dedup {
+					m.remote.reliable.dedup.unmark(msg.nodeId, sendName.MessageId)
+				}
+			} else {
+				resp.HasError = false
+			}
+			if ackMode != AckMode_AckEach && sendError != nil {
+				log.Println("actor.Remote handled incoming message, unacknowledged send error,", sendError)
+			}
+		}
+	case ControlType_CAskName:
+		{
+			// Validation
+			askNameWrapper := msg.inMessage.GetAskName()
+			resp := &AskName_Response{
+				HasError:   true,
+				AnswerData: &DataContentType{},
+			}
+			replyMessage.Type = ControlType_CAskName
+			replyMessage.Content = &ConnMessage_AskName{
+				AskName: &AskName{
+					Data: &AskName_Resp{
+						Resp: resp,
+					},
+				},
+			}
+			if askNameWrapper == nil {
+				log.Println("actor.Remote handled incoming message, empty ask message error,", msg)
+				resp.ErrorMessage = "Empty message"
+				break
+			}
+			askName := askNameWrapper.GetReq()
+			if askName == nil || askName.AskData == nil || askName.AnswerData == nil {
+				log.Println("actor.Remote handled incoming message, empty ask message request error,", msg)
+				resp.ErrorMessage = "Empty message request"
+				break
+			}
+
+			// Get local actor by name
+			askFromRef := m.remoteSender(msg.inConn, msg.nodeId, askName.FromId, askName.FromName)
+			localRef := m.remote.sys.ByName(askName.ToName)
+			if localRef == nil {
+				resp.ErrorMessage = "Actor name not found"
+				m.remoteDeadLetter(askFromRef, askName.ToName, askName.AskData)
+				break
+			}
+
+			// Process ask message
+			askMessage, err := decodeContent(askName.AskData)
+			if err != nil {
+				resp.ErrorMessage = err.Error()
+				break
+			}
+			askMessage = m.envelope(msg.inConn, msg.nodeId, askName.Envelope, askMessage)
+			prototype, err := decodeContent(askName.AnswerData)
+			if err != nil {
+				resp.ErrorMessage = err.Error()
+				break
+			}
+			// Ask local actor, with an answer of the same type as the prototype
+			answer := reflect.New(reflect.TypeOf(prototype))
+			answer.Elem().Set(reflect.ValueOf(prototype))
+			answerError := localRef.Ask(askFromRef, askMessage, answer.Interface())
+			if answerError == nil {
+				resp.AnswerData, answerError = encodeContent(answer.Elem().Interface())
+			}
+			// Error
+			if answerError != nil {
+				resp.ErrorMessage = answerError.Error()
+			} else {
+				resp.HasError = false
+			}
+		}
+	case ControlType_CGetName:
+		{
+			// Validation
+			getNameWrapper := msg.inMessage.GetGetName()
+			resp := &GetName_Response{
+				Has: false,
+			}
+			replyMessage.Type = ControlType_CGetName
+			replyMessage.Content = &ConnMessage_GetName{
+				GetName: &GetName{
+					Data: &GetName_Resp{
+						Resp: resp,
+					},
+				},
+			}
+			if getNameWrapper == nil {
+				log.Println("actor.Remote handled incoming message, empty get message error,", msg)
+				resp.ErrorMessage = "Empty message"
+				break
+			}
+			getName := getNameWrapper.GetReq()
+			if getName == nil || getName.Name == "" {
+				log.Println("actor.Remote handled incoming message, empty get message request error,", msg)
+				resp.ErrorMessage = "Empty message request"
+				break
+			}
+
+			// Process get message
+			if lr := m.remote.sys.ByName(getName.Name); lr != nil {
+				resp.Has = true
+				resp.ActorId = lr.id.id
+			} else {
+				resp.ErrorMessage = "Actor name not found"
+			}
+		}
+	case ControlType_CPublish:
+		// Published event is not acknowledged
+		m.remote.sys.events.handleRemotePublish(msg.inMessage.GetPublish())
+		return
+	default:
+		log.Println("actor.Remote handled incoming message type error,", msg)
+	}
+	// Message via outgoing connection is posted, it cannot be replied
+	if msg.inConn == nil {
+		return
+	}
+	switch ackMode {
+	case AckMode_AckNone:
+		return
+	case AckMode_AckBatch:
+		msg.inConn.ackLater(msg.inMessage.SequenceId)
+		return
+	}
+	if err := msg.reply(replyMessage); err != nil {
+		log.Println("actor.Remote handled incoming message reply error,", err)
+	}
+}
+
+// Reference of the sender of an incoming message, it is nil if sender is unknown.
+// Replies to the sender are sent back via the incoming connection in, addressed
+// by the id of sender, so that the node needs not to have been dialed. Replies
+// are fire-and-forget, messages with other acknowledgment modes, Ask and replies
+// after in has been closed are sent via the connection to its node, if the node
+// has been dialed.
+func (m *conn) remoteSender(in *inNode, nodeId, fromId uint32, fromName string) Ref {
+	if fromId == 0 {
+		return nil
+	}
+	ref := &RemoteRef{
+		id: Id{
+			node: nodeId,
+			id:   fromId,
+			name: fromName,
+		},
+		node: m.getOutConn(nodeId),
+		in:   in,
+	}
+	if in != nil {
+		ref.ackMode = AckMode_AckNone
 	}
+	return ref
+}
+
+// Incoming message to a name which is not found is a dead letter.
+func (m *conn) remoteDeadLetter(sender Ref, toName string, data *DataContentType) {
+	content, err := decodeContent(data)
+	if err != nil {
+		content = data
+	}
+	m.remote.sys.deadLetter(sender, Id{name: toName}, content, ErrRemoteActorNotFound)
 }
 
 func (m *conn) inConnHandler() {
@@ -357,10 +456,12 @@ func (m *conn) inConnHandler() {
 			}
 			n.reader.init(&n.conn)
 			n.writer.init(&n.conn)
+			n.detector.init(m.heartbeatInterval, m.heartbeatMissed)
 
 			// todo connect back
 			defer func() {
 				n.conn.safeClose()
+				m.inNodeClosed(n)
 			}()
 			// receive auth
 			isAuth, sequenceId := false, uint64(0)
@@ -394,10 +495,12 @@ func (m *conn) inConnHandler() {
 						break
 					}
 					n.nodeId = req.FromNodeId
+					m.inConnLock.Lock()
 					if oldN, has := m.inConn[n.nodeId]; has {
 						oldN.conn.safeClose()
 					}
 					m.inConn[n.nodeId] = n
+					m.inConnLock.Unlock()
 					sequenceId = packet.SequenceId
 					// todo reply okay
 					isAuth = true
@@ -426,15 +529,25 @@ func (m *conn) inConnHandler() {
 			if !isAuth {
 				return
 			}
+			go n.watch()
 			// reply
 			for {
 				packet, more := <-n.reader.recvCh
 				if !more {
 					return
 				}
+				// Every packet proves that the remote node is alive.
+				n.detector.heartbeat()
+				if packet.Type == ControlType_CHeartbeat {
+					if err := n.replyHeartbeat(packet); err != nil {
+						log.Println("actor.Remote in node reply heartbeat error,", err)
+					}
+					continue
+				}
 				m.inMessageCh <- &inReply{
 					inMessage: packet,
 					inConn:    n,
+					nodeId:    n.nodeId,
 				}
 			}
 		}()
@@ -469,7 +582,6 @@ func (m *conn) getOutConnOrDial(nodeId uint32, auth string, nw Network, addr str
 
 	// todo
 	n = &outNode{
-		ready:   false,
 		global:  m.remote,
 		nodeId:  nodeId,
 		nw:      nw,
@@ -487,9 +599,38 @@ func (m *conn) getOutConnOrDial(nodeId uint32, auth string, nw Network, addr str
 		return nil, err
 	}
 	log.Println("actor.Remote.getOutConnOrDial new conn")
+	// resend before the new conn is visible to other senders
+	m.resendRequeued(n)
 	return n, nil
 }
 
+// Keep batch acknowledged requests of a node which has been down.
+func (m *conn) requeue(nodeId uint32, reqs []*ConnMessage) {
+	if len(reqs) == 0 {
+		return
+	}
+	m.unackedLock.Lock()
+	if m.unacked != nil {
+		m.unacked[nodeId] = append(m.unacked[nodeId], reqs...)
+	}
+	m.unackedLock.Unlock()
+}
+
+// Resend requeued requests of the node of a new out node.
+func (m *conn) resendRequeued(n *outNode) {
+	m.unackedLock.Lock()
+	reqs := m.unacked[n.nodeId]
+	delete(m.unacked, n.nodeId)
+	m.unackedLock.Unlock()
+	for i, req := range reqs {
+		if _, err := n.request(req, true); err != nil {
+			log.Println("actor.Remote out node resend requeued error,", err)
+			m.requeue(n.nodeId, reqs[i:])
+			return
+		}
+	}
+}
+
 func (m *conn) close() {
 	m.ready = false
 	m.listener.Close()
@@ -502,14 +643,113 @@ func (m *conn) close() {
 	m.inConnLock.Unlock()
 	m.outConnLock.Lock()
 	for nodeId, node := range m.outConn {
-		node.conn.safeClose()
-		for id, seq := range node.seq {
-			seq.respCh <- nil
-			delete(node.seq, id)
-		}
+		node.down(ErrConnError)
 		delete(m.outConn, nodeId)
 	}
 	m.outConnLock.Unlock()
+	m.unackedLock.Lock()
+	for nodeId, reqs := range m.unacked {
+		log.Println("actor.Remote closed, unacknowledged messages lost,", nodeId, len(reqs))
+	}
+	m.unacked = nil
+	m.unackedLock.Unlock()
+}
+
+// Register a callback which will be called when a remote node is detected down,
+// either its outgoing connection has been broken, or its heartbeats have been missed.
+func (m *conn) onNodeDown(fn func(nodeId uint32)) {
+	m.nodeDownLock.Lock()
+	m.nodeDownFn = append(m.nodeDownFn, fn)
+	m.nodeDownLock.Unlock()
+}
+
+func (m *conn) notifyNodeDown(nodeId uint32) {
+	m.nodeDownLock.RLock()
+	fns := m.nodeDownFn
+	m.nodeDownLock.RUnlock()
+	log.Println("actor.Remote node down,", nodeId)
+	for _, fn := range fns {
+		fn(nodeId)
+	}
+}
+
+// Out node has lost its connection, or missed heartbeats of remote node.
+// Fail all pending requests immediately, instead of waiting for requestTimeout.
+func (m *conn) outNodeDown(n *outNode) {
+	m.outConnLock.Lock()
+	if cur, has := m.outConn[n.nodeId]; has && cur == n {
+		delete(m.outConn, n.nodeId)
+	}
+	m.outConnLock.Unlock()
+	if !n.down(ErrRemoteNodeDown) {
+		return
+	}
+	m.notifyNodeDown(n.nodeId)
+}
+
+// In node has been closed, if it is caused by missing heartbeats, the remote node
+// is treated as down, the out node to the same remote node will be failed as well.
+func (m *conn) inNodeClosed(n *inNode) {
+	if n.nodeId == 0 {
+		return
+	}
+	m.inConnLock.Lock()
+	if cur, has := m.inConn[n.nodeId]; has && cur == n {
+		delete(m.inConn, n.nodeId)
+	}
+	m.inConnLock.Unlock()
+	if n.detector.isAvailable() {
+		return
+	}
+	if out := m.getOutConn(n.nodeId); out != nil {
+		m.outNodeDown(out)
+	} else {
+		m.notifyNodeDown(n.nodeId)
+	}
+}
+
+//
+// failure detector
+//
+
+// A missed-beat failure detector, the remote node is considered down, if there is no
+// packet has been received during the period of interval * maxMissed.
+type failureDetector struct {
+	sync.Mutex
+	interval   time.Duration
+	maxMissed  int
+	lastBeatAt time.Time
+}
+
+func (m *failureDetector) init(interval time.Duration, maxMissed int) {
+	m.interval = interval
+	m.maxMissed = maxMissed
+	m.lastBeatAt = time.Now()
+}
+
+func (m *failureDetector) heartbeat() {
+	m.Lock()
+	m.lastBeatAt = time.Now()
+	m.Unlock()
+}
+
+func (m *failureDetector) isAvailable() bool {
+	m.Lock()
+	defer m.Unlock()
+	return time.Since(m.lastBeatAt) < m.interval*time.Duration(m.maxMissed)
+}
+
+func newHeartbeat(direction Direction, sequenceId uint64) *ConnMessage {
+	return &ConnMessage{
+		SequenceId: sequenceId,
+		Type:       ControlType_CHeartbeat,
+		Direction:  direction,
+		Content: &ConnMessage_Heartbeat{
+			Heartbeat: &Heartbeat{
+				Timestamp: time.Now().UnixNano(),
+			},
+		},
+	}
 }
 
 //
@@ -517,7 +757,7 @@ func (m *conn) close() {
 //
 
 type outNode struct {
-	ready   bool
+	ready   int32 // atomic, read by references without lock
 	global  *remoteManager
 	nodeId  uint32
 	nw      Network
@@ -528,17 +768,53 @@ type outNode struct {
 	seq     map[uint64]*seqWrapper
 	seqId   uint64
 	seqLock sync.Mutex
+	// heartbeat
+	detector failureDetector
+	downOnce sync.Once
+	downCh   chan struct{}
 }
 
 type seqWrapper struct {
 	req       *ConnMessage
 	respCh    chan *ConnMessage
-	canceled  bool
+	canceled  int32 // atomic, set by the timed out requester
 	createdAt time.Time
+	err       error
+	// Nobody waits for the response of a batch acknowledged request, it will be
+	// resent if it has not been acknowledged in requestTimeout.
+	batch bool
+}
+
+// Fail a pending request, err will be returned to the requester.
+func (m *seqWrapper) fail(err error) {
+	m.err = err
+	m.respCh <- nil
+}
+
+// Requester has stopped waiting for the response.
+func (m *seqWrapper) cancel() {
+	atomic.StoreInt32(&m.canceled, 1)
+}
+
+func (m *seqWrapper) isCanceled() bool {
+	return atomic.LoadInt32(&m.canceled) == 1
+}
+
+func (m *outNode) isReady() bool {
+	return atomic.LoadInt32(&m.ready) == 1
+}
+
+func (m *outNode) setReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&m.ready, 1)
+	} else {
+		atomic.StoreInt32(&m.ready, 0)
+	}
 }
 
 func (m *outNode) dial(password string) (err error) {
-	m.ready = false
+	m.setReady(false)
+	m.downCh = make(chan struct{})
 	m.conn.closed = false
 	m.conn.Conn, err = net.Dial(string(m.nw), m.addr)
 	if err != nil {
@@ -555,8 +831,10 @@ func (m *outNode) dial(password string) (err error) {
 		return err
 	}
 
-	m.ready = true
+	m.setReady(true)
+	m.detector.init(m.global.conn.heartbeatInterval, m.global.conn.heartbeatMissed)
 	go m.loop()
+	go m.heartbeat()
 	return nil
 }
 
@@ -601,25 +879,169 @@ func (m *outNode) auth(nodeId uint32, password string) error {
 }
 
 func (m *outNode) loop() {
+	defer m.global.conn.outNodeDown(m)
 	for {
 		packet, more := <-m.reader.recvCh
 		if !more {
 			return
 		}
+		// Every packet proves that the remote node is alive.
+		m.detector.heartbeat()
+		switch packet.Type {
+		case ControlType_CHeartbeat:
+			continue
+		case ControlType_CSendAck:
+			m.acked(packet.GetSendAck().GetSequenceIds())
+			continue
+		}
+		// Remote node sends back via this connection, such as replying a sender
+		if packet.Direction == Direction_Request {
+			if packet.Type != ControlType_CSendName {
+				log.Println("actor.Remote out node receive unsupported request,", packet)
+				continue
+			}
+			m.global.conn.inMessageCh <- &inReply{
+				inMessage: packet,
+				nodeId:    m.nodeId,
+			}
+			continue
+		}
+		m.seqLock.Lock()
 		seq, has := m.seq[packet.SequenceId]
+		if has {
+			delete(m.seq, packet.SequenceId)
+		}
+		m.seqLock.Unlock()
 		if !has {
 			log.Println("actor.Remote out node receive unknown sequence,", packet)
 			continue
 		}
-		m.seqLock.Lock()
-		delete(m.seq, packet.SequenceId)
-		m.seqLock.Unlock()
+		if seq.isCanceled() {
+			m.expiredResponse(seq, packet)
+			continue
+		}
 		seq.respCh <- packet
 	}
 }
 
+// Answer of an ask which has timed out is a dead letter.
+func (m *outNode) expiredResponse(w *seqWrapper, packet *ConnMessage) {
+	ask := w.req.GetAskName().GetReq()
+	resp := packet.GetAskName().GetResp()
+	if ask == nil || resp == nil || resp.AnswerData == nil {
+		return
+	}
+	content, err := decodeContent(resp.AnswerData)
+	if err != nil {
+		content = resp.AnswerData
+	}
+	sender := &RemoteRef{
+		id: Id{
+			node: m.nodeId,
+			id:   0,
+			name: ask.ToName,
+		},
+		node: m,
+	}
+	m.global.sys.deadLetter(sender, Id{id: ask.FromId, name: ask.FromName}, content, ErrRemoteTimeout)
+}
+
+// Send heartbeat periodically, and check whether remote node has missed its beats.
+func (m *outNode) heartbeat() {
+	ticker := time.NewTicker(m.detector.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.downCh:
+			return
+		case <-ticker.C:
+			if !m.detector.isAvailable() {
+				log.Println("actor.Remote out node missed heartbeats,", m.nodeId)
+				m.global.conn.outNodeDown(m)
+				return
+			}
+			if err := m.writer.send(newHeartbeat(Direction_Request, 0)); err != nil {
+				log.Println("actor.Remote out node send heartbeat error,", err)
+			}
+			m.resendUnacked()
+		}
+	}
+}
+
+// Remove batch acknowledged requests.
+func (m *outNode) acked(ids []uint64) {
+	m.seqLock.Lock()
+	for _, id := range ids {
+		if w, has := m.seq[id]; has && w.batch {
+			delete(m.seq, id)
+		}
+	}
+	m.seqLock.Unlock()
+}
+
+// Resend batch acknowledged requests, which have not been acknowledged in requestTimeout.
+func (m *outNode) resendUnacked() {
+	var resend []*seqWrapper
+	m.seqLock.Lock()
+	for _, w := range m.seq {
+		if w.batch && time.Since(w.createdAt) > requestTimeout {
+			w.createdAt = time.Now()
+			resend = append(resend, w)
+		}
+	}
+	m.seqLock.Unlock()
+	for _, w := range resend {
+		if err := m.writer.send(w.req); err != nil {
+			log.Println("actor.Remote out node resend error,", err)
+			return
+		}
+	}
+}
+
+// Mark out node down, close its connection and fail all pending requests.
+// Batch acknowledged requests are requeued, and resent after the node has been
+// dialed again. Returns false if the out node has been down already.
+func (m *outNode) down(err error) (first bool) {
+	m.downOnce.Do(func() {
+		first = true
+		m.setReady(false)
+		close(m.downCh)
+		m.conn.safeClose()
+		var unacked []*ConnMessage
+		m.seqLock.Lock()
+		for id, w := range m.seq {
+			if w.batch {
+				unacked = append(unacked, w.req)
+			} else {
+				w.fail(err)
+			}
+			delete(m.seq, id)
+		}
+		m.seqLock.Unlock()
+		sort.Slice(unacked, func(i, j int) bool {
+			return unacked[i].SequenceId < unacked[j].SequenceId
+		})
+		m.global.conn.requeue(m.nodeId, unacked)
+	})
+	return first
+}
+
 func (m *outNode) send(message *ConnMessage) (*seqWrapper, error) {
-	if !m.ready {
+	return m.request(message, false)
+}
+
+// Send without registering a sequence, the remote node will not reply.
+func (m *outNode) post(message *ConnMessage) error {
+	if !m.isReady() {
+		return ErrGlobalNodeNotReady
+	}
+	message.SequenceId = 0
+	message.Direction = Direction_Request
+	return m.writer.send(message)
+}
+
+func (m *outNode) request(message *ConnMessage, batch bool) (*seqWrapper, error) {
+	if !m.isReady() {
 		return nil, ErrGlobalNodeNotReady
 	}
 
@@ -637,6 +1059,7 @@ func (m *outNode) send(message *ConnMessage) (*seqWrapper, error) {
 		req:       message,
 		respCh:    make(chan *ConnMessage, 1),
 		createdAt: time.Now(),
+		batch:     batch,
 	}
 	m.seq[seqId] = w
 	m.seqLock.Unlock()
@@ -651,14 +1074,7 @@ func (m *outNode) send(message *ConnMessage) (*seqWrapper, error) {
 }
 
 func (m *outNode) close() {
-	m.ready = false
-	m.conn.safeClose()
-	m.seqLock.Lock()
-	for id, w := range m.seq {
-		w.respCh <- nil
-		delete(m.seq, id)
-	}
-	m.seqLock.Unlock()
+	m.down(ErrConnError)
 }
 
 //
@@ -666,17 +1082,99 @@ func (m *outNode) close() {
 //
 
 type inNode struct {
-	ready  bool
-	global *remoteManager
-	nodeId uint32
-	conn   connSafe
-	reader connReader
-	writer connWriter
+	ready    bool
+	global   *remoteManager
+	nodeId   uint32
+	conn     connSafe
+	reader   connReader
+	writer   connWriter
+	detector failureDetector
+	acks     []uint64
+	acksLock sync.Mutex
+}
+
+// Close the connection if remote node has missed its heartbeats.
+func (m *inNode) watch() {
+	ticker := time.NewTicker(m.detector.interval)
+	defer ticker.Stop()
+	ackTicker := time.NewTicker(sendAckInterval)
+	defer ackTicker.Stop()
+	for {
+		select {
+		case <-m.conn.done():
+			return
+		case <-ticker.C:
+			if !m.detector.isAvailable() {
+				log.Println("actor.Remote in node missed heartbeats,", m.nodeId)
+				m.conn.safeClose()
+				return
+			}
+		case <-ackTicker.C:
+			m.flushAcks()
+		}
+	}
+}
+
+// Acknowledge a batch acknowledged request later, with other requests together.
+func (m *inNode) ackLater(sequenceId uint64) {
+	m.acksLock.Lock()
+	m.acks = append(m.acks, sequenceId)
+	full := len(m.acks) >= sendAckBatchSize
+	m.acksLock.Unlock()
+	if full {
+		m.flushAcks()
+	}
+}
+
+func (m *inNode) flushAcks() {
+	m.acksLock.Lock()
+	acks := m.acks
+	m.acks = nil
+	m.acksLock.Unlock()
+	if len(acks) == 0 {
+		return
+	}
+	if err := m.writer.send(&ConnMessage{
+		Type:      ControlType_CSendAck,
+		Direction: Direction_Response,
+		Content: &ConnMessage_SendAck{
+			SendAck: &SendAck{
+				SequenceIds: acks,
+			},
+		},
+	}); err != nil {
+		log.Println("actor.Remote in node send ack error,", err)
+	}
+}
+
+func (m *inNode) replyHeartbeat(packet *ConnMessage) error {
+	return m.writer.send(newHeartbeat(Direction_Response, packet.SequenceId))
+}
+
+func (m *inNode) isClosed() bool {
+	select {
+	case <-m.conn.done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Send a request back to the remote node, the remote node will not reply.
+func (m *inNode) post(message *ConnMessage) error {
+	if m.isClosed() {
+		return ErrConnError
+	}
+	message.SequenceId = 0
+	message.Direction = Direction_Request
+	return m.writer.send(message)
 }
 
+// Incoming message, inConn is nil if it is sent via outgoing connection.
 type inReply struct {
 	inMessage *ConnMessage
 	inConn    *inNode
+	nodeId    uint32
 }
 
 func (m *inReply) reply(message *ConnMessage) error {
@@ -823,14 +1321,20 @@ func (m *connReader) handleBuffer(buf []byte) (buffers [][]byte, err error) {
 // conn writer
 //
 
+// Messages are marshaled by the goroutines which call send, then queued to sendCh.
+// A dedicated writer goroutine drains the queue, coalesces queued frames into a
+// buffered write, so frames of concurrent senders would never interleave.
 type connWriter struct {
 	conn   *connSafe
-	header []byte
+	header []byte // owned by writer goroutine
+	sendCh chan []byte
 }
 
 func (m *connWriter) init(conn *connSafe) {
 	m.conn = conn
 	m.header = make([]byte, packetHeaderSize)
+	m.sendCh = make(chan []byte, writeChannelSize)
+	go m.loop()
 }
 
 func (m *connWriter) send(msg *ConnMessage) error {
@@ -838,8 +1342,55 @@ func (m *connWriter) send(msg *ConnMessage) error {
 	if err != nil {
 		return err
 	}
+	if len(buf) > packetSizeLimit {
+		return ErrPacketInvalid
+	}
+	select {
+	case m.sendCh <- buf:
+		return nil
+	case <-m.conn.done():
+		return ErrConnError
+	}
+}
+
+func (m *connWriter) loop() {
+	w := bufio.NewWriterSize(m.conn, writeBufferSize)
+	defer m.conn.safeClose()
+	for {
+		select {
+		case buf := <-m.sendCh:
+			if err := m.write(w, buf); err != nil {
+				log.Println("actor.Remote conn write error,", err)
+				return
+			}
+			// Coalesce frames which have been queued during last write.
+			for more := true; more; {
+				select {
+				case buf := <-m.sendCh:
+					if err := m.write(w, buf); err != nil {
+						log.Println("actor.Remote conn write error,", err)
+						return
+					}
+				default:
+					more = false
+				}
+			}
+			if err := w.Flush(); err != nil {
+				log.Println("actor.Remote conn flush error,", err)
+				return
+			}
+		case <-m.conn.done():
+			return
+		}
+	}
+}
+
+func (m *connWriter) write(w *bufio.Writer, buf []byte) error {
 	binary.PutVarint(m.header[:packetHeaderSize], int64(len(buf)))
-	_, err = m.conn.Write(append(m.header, buf...))
+	if _, err := w.Write(m.header); err != nil {
+		return err
+	}
+	_, err := w.Write(buf)
 	return err
 }
 
@@ -851,6 +1402,7 @@ type connSafe struct {
 	net.Conn
 	sync.Mutex
 	closed bool
+	doneCh chan struct{}
 }
 
 func (m *connSafe) safeClose() {
@@ -858,6 +1410,22 @@ func (m *connSafe) safeClose() {
 	if !m.closed {
 		m.closed = true
 		m.Close()
+		if m.doneCh != nil {
+			close(m.doneCh)
+		}
 	}
 	m.Unlock()
 }
+
+// Returns a channel which will be closed after the connection has been closed.
+func (m *connSafe) done() <-chan struct{} {
+	m.Lock()
+	defer m.Unlock()
+	if m.doneCh == nil {
+		m.doneCh = make(chan struct{})
+		if m.closed {
+			close(m.doneCh)
+		}
+	}
+	return m.doneCh
+}
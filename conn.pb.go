@@ -24,11 +24,14 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 type ControlType int32
 
 const (
-	ControlType_CUnknown  ControlType = 0
-	ControlType_CAuth     ControlType = 2
-	ControlType_CSendName ControlType = 3
-	ControlType_CAskName  ControlType = 4
-	ControlType_CGetName  ControlType = 5
+	ControlType_CUnknown   ControlType = 0
+	ControlType_CAuth      ControlType = 2
+	ControlType_CSendName  ControlType = 3
+	ControlType_CAskName   ControlType = 4
+	ControlType_CGetName   ControlType = 5
+	ControlType_CHeartbeat ControlType = 6
+	ControlType_CSendAck   ControlType = 7
+	ControlType_CPublish   ControlType = 8
 )
 
 var ControlType_name = map[int32]string{
@@ -37,14 +40,20 @@ var ControlType_name = map[int32]string{
 	3: "CSendName",
 	4: "CAskName",
 	5: "CGetName",
+	6: "CHeartbeat",
+	7: "CSendAck",
+	8: "CPublish",
 }
 
 var ControlType_value = map[string]int32{
-	"CUnknown":  0,
-	"CAuth":     2,
-	"CSendName": 3,
-	"CAskName":  4,
-	"CGetName":  5,
+	"CUnknown":   0,
+	"CAuth":      2,
+	"CSendName":  3,
+	"CAskName":   4,
+	"CGetName":   5,
+	"CHeartbeat": 6,
+	"CSendAck":   7,
+	"CPublish":   8,
 }
 
 func (x ControlType) String() string {
@@ -83,26 +92,22 @@ func (Direction) EnumDescriptor() ([]byte, []int) {
 type DataType int32
 
 const (
-	DataType_ProtoBuf   DataType = 0
-	DataType_Bool       DataType = 1
-	DataType_Bytes      DataType = 2
-	DataType_String     DataType = 3
-	DataType_Int        DataType = 4
-	DataType_Int8       DataType = 5
-	DataType_Int16      DataType = 6
-	DataType_Int32      DataType = 7
-	DataType_Int64      DataType = 8
-	DataType_UInt       DataType = 9
-	DataType_UInt8      DataType = 10
-	DataType_UInt16     DataType = 11
-	DataType_UInt32     DataType = 12
-	DataType_UInt64     DataType = 13
-	DataType_Float32    DataType = 14
-	DataType_Float64    DataType = 15
-	DataType_Complex64  DataType = 16
-	DataType_Complex128 DataType = 17
-	DataType_Slice      DataType = 18
-	DataType_Map        DataType = 19
+	DataType_ProtoBuf DataType = 0
+	DataType_Bool     DataType = 1
+	DataType_Bytes    DataType = 2
+	DataType_String   DataType = 3
+	DataType_Int      DataType = 4
+	DataType_Int8     DataType = 5
+	DataType_Int16    DataType = 6
+	DataType_Int32    DataType = 7
+	DataType_Int64    DataType = 8
+	DataType_UInt     DataType = 9
+	DataType_UInt8    DataType = 10
+	DataType_UInt16   DataType = 11
+	DataType_UInt32   DataType = 12
+	DataType_UInt64   DataType = 13
+	DataType_Float32  DataType = 14
+	DataType_Float64  DataType = 15
 )
 
 var DataType_name = map[int32]string{
@@ -122,33 +127,25 @@ var DataType_name = map[int32]string{
 	13: "UInt64",
 	14: "Float32",
 	15: "Float64",
-	16: "Complex64",
-	17: "Complex128",
-	18: "Slice",
-	19: "Map",
 }
 
 var DataType_value = map[string]int32{
-	"ProtoBuf":   0,
-	"Bool":       1,
-	"Bytes":      2,
-	"String":     3,
-	"Int":        4,
-	"Int8":       5,
-	"Int16":      6,
-	"Int32":      7,
-	"Int64":      8,
-	"UInt":       9,
-	"UInt8":      10,
-	"UInt16":     11,
-	"UInt32":     12,
-	"UInt64":     13,
-	"Float32":    14,
-	"Float64":    15,
-	"Complex64":  16,
-	"Complex128": 17,
-	"Slice":      18,
-	"Map":        19,
+	"ProtoBuf": 0,
+	"Bool":     1,
+	"Bytes":    2,
+	"String":   3,
+	"Int":      4,
+	"Int8":     5,
+	"Int16":    6,
+	"Int32":    7,
+	"Int64":    8,
+	"UInt":     9,
+	"UInt8":    10,
+	"UInt16":   11,
+	"UInt32":   12,
+	"UInt64":   13,
+	"Float32":  14,
+	"Float64":  15,
 }
 
 func (x DataType) String() string {
@@ -159,6 +156,62 @@ func (DataType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_f401a58c1fc7ceef, []int{2}
 }
 
+type AckMode int32
+
+const (
+	AckMode_AckEach  AckMode = 0
+	AckMode_AckNone  AckMode = 1
+	AckMode_AckBatch AckMode = 2
+)
+
+var AckMode_name = map[int32]string{
+	0: "AckEach",
+	1: "AckNone",
+	2: "AckBatch",
+}
+
+var AckMode_value = map[string]int32{
+	"AckEach":  0,
+	"AckNone":  1,
+	"AckBatch": 2,
+}
+
+func (x AckMode) String() string {
+	return proto.EnumName(AckMode_name, int32(x))
+}
+
+func (AckMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{3}
+}
+
+type Delivery int32
+
+const (
+	Delivery_AtMostOnce  Delivery = 0
+	Delivery_AtLeastOnce Delivery = 1
+	Delivery_ExactlyOnce Delivery = 2
+)
+
+var Delivery_name = map[int32]string{
+	0: "AtMostOnce",
+	1: "AtLeastOnce",
+	2: "ExactlyOnce",
+}
+
+var Delivery_value = map[string]int32{
+	"AtMostOnce":  0,
+	"AtLeastOnce": 1,
+	"ExactlyOnce": 2,
+}
+
+func (x Delivery) String() string {
+	return proto.EnumName(Delivery_name, int32(x))
+}
+
+func (Delivery) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{4}
+}
+
 type ConnMessage struct {
 	SequenceId uint64      `protobuf:"varint,1,opt,name=sequence_id,json=sequenceId,proto3" json:"sequence_id,omitempty"`
 	Type       ControlType `protobuf:"varint,2,opt,name=type,proto3,enum=actor.ControlType" json:"type,omitempty"`
@@ -168,6 +221,9 @@ type ConnMessage struct {
 	//	*ConnMessage_GetName
 	//	*ConnMessage_SendName
 	//	*ConnMessage_AskName
+	//	*ConnMessage_Heartbeat
+	//	*ConnMessage_SendAck
+	//	*ConnMessage_Publish
 	Content              isConnMessage_Content `protobuf_oneof:"content"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
@@ -240,6 +296,18 @@ type ConnMessage_AskName struct {
 	AskName *AskName `protobuf:"bytes,7,opt,name=ask_name,json=askName,proto3,oneof"`
 }
 
+type ConnMessage_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,8,opt,name=heartbeat,proto3,oneof"`
+}
+
+type ConnMessage_SendAck struct {
+	SendAck *SendAck `protobuf:"bytes,9,opt,name=send_ack,json=sendAck,proto3,oneof"`
+}
+
+type ConnMessage_Publish struct {
+	Publish *Publish `protobuf:"bytes,10,opt,name=publish,proto3,oneof"`
+}
+
 func (*ConnMessage_Auth) isConnMessage_Content() {}
 
 func (*ConnMessage_GetName) isConnMessage_Content() {}
@@ -248,6 +316,12 @@ func (*ConnMessage_SendName) isConnMessage_Content() {}
 
 func (*ConnMessage_AskName) isConnMessage_Content() {}
 
+func (*ConnMessage_Heartbeat) isConnMessage_Content() {}
+
+func (*ConnMessage_SendAck) isConnMessage_Content() {}
+
+func (*ConnMessage_Publish) isConnMessage_Content() {}
+
 func (m *ConnMessage) GetContent() isConnMessage_Content {
 	if m != nil {
 		return m.Content
@@ -283,6 +357,27 @@ func (m *ConnMessage) GetAskName() *AskName {
 	return nil
 }
 
+func (m *ConnMessage) GetHeartbeat() *Heartbeat {
+	if x, ok := m.GetContent().(*ConnMessage_Heartbeat); ok {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+func (m *ConnMessage) GetSendAck() *SendAck {
+	if x, ok := m.GetContent().(*ConnMessage_SendAck); ok {
+		return x.SendAck
+	}
+	return nil
+}
+
+func (m *ConnMessage) GetPublish() *Publish {
+	if x, ok := m.GetContent().(*ConnMessage_Publish); ok {
+		return x.Publish
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*ConnMessage) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -290,6 +385,9 @@ func (*ConnMessage) XXX_OneofWrappers() []interface{} {
 		(*ConnMessage_GetName)(nil),
 		(*ConnMessage_SendName)(nil),
 		(*ConnMessage_AskName)(nil),
+		(*ConnMessage_Heartbeat)(nil),
+		(*ConnMessage_SendAck)(nil),
+		(*ConnMessage_Publish)(nil),
 	}
 }
 
@@ -467,6 +565,45 @@ func (m *Auth_Response) GetIsAuth() bool {
 	return false
 }
 
+type Heartbeat struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Heartbeat) Reset()         { *m = Heartbeat{} }
+func (m *Heartbeat) String() string { return proto.CompactTextString(m) }
+func (*Heartbeat) ProtoMessage()    {}
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{2}
+}
+
+func (m *Heartbeat) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Heartbeat.Unmarshal(m, b)
+}
+func (m *Heartbeat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Heartbeat.Marshal(b, m, deterministic)
+}
+func (m *Heartbeat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Heartbeat.Merge(m, src)
+}
+func (m *Heartbeat) XXX_Size() int {
+	return xxx_messageInfo_Heartbeat.Size(m)
+}
+func (m *Heartbeat) XXX_DiscardUnknown() {
+	xxx_messageInfo_Heartbeat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Heartbeat proto.InternalMessageInfo
+
+func (m *Heartbeat) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
 type DataContentType struct {
 	Type DataType `protobuf:"varint,1,opt,name=type,proto3,enum=actor.DataType" json:"type,omitempty"`
 	// Types that are valid to be assigned to Content:
@@ -487,7 +624,7 @@ func (m *DataContentType) Reset()         { *m = DataContentType{} }
 func (m *DataContentType) String() string { return proto.CompactTextString(m) }
 func (*DataContentType) ProtoMessage()    {}
 func (*DataContentType) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{2}
+	return fileDescriptor_f401a58c1fc7ceef, []int{3}
 }
 
 func (m *DataContentType) XXX_Unmarshal(b []byte) error {
@@ -644,7 +781,7 @@ func (m *SendName) Reset()         { *m = SendName{} }
 func (m *SendName) String() string { return proto.CompactTextString(m) }
 func (*SendName) ProtoMessage()    {}
 func (*SendName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{3}
+	return fileDescriptor_f401a58c1fc7ceef, []int{4}
 }
 
 func (m *SendName) XXX_Unmarshal(b []byte) error {
@@ -715,6 +852,11 @@ type SendName_Request struct {
 	FromName             string           `protobuf:"bytes,2,opt,name=from_name,json=fromName,proto3" json:"from_name,omitempty"`
 	ToName               string           `protobuf:"bytes,3,opt,name=to_name,json=toName,proto3" json:"to_name,omitempty"`
 	SendData             *DataContentType `protobuf:"bytes,4,opt,name=send_data,json=sendData,proto3" json:"send_data,omitempty"`
+	AckMode              AckMode          `protobuf:"varint,5,opt,name=ack_mode,json=ackMode,proto3,enum=actor.AckMode" json:"ack_mode,omitempty"`
+	MessageId            string           `protobuf:"bytes,6,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Delivery             Delivery         `protobuf:"varint,7,opt,name=delivery,proto3,enum=actor.Delivery" json:"delivery,omitempty"`
+	Envelope             *ConnEnvelope    `protobuf:"bytes,8,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	ToId                 uint32           `protobuf:"varint,9,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
@@ -724,7 +866,7 @@ func (m *SendName_Request) Reset()         { *m = SendName_Request{} }
 func (m *SendName_Request) String() string { return proto.CompactTextString(m) }
 func (*SendName_Request) ProtoMessage()    {}
 func (*SendName_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{3, 0}
+	return fileDescriptor_f401a58c1fc7ceef, []int{4, 0}
 }
 
 func (m *SendName_Request) XXX_Unmarshal(b []byte) error {
@@ -773,6 +915,41 @@ func (m *SendName_Request) GetSendData() *DataContentType {
 	return nil
 }
 
+func (m *SendName_Request) GetAckMode() AckMode {
+	if m != nil {
+		return m.AckMode
+	}
+	return AckMode_AckEach
+}
+
+func (m *SendName_Request) GetMessageId() string {
+	if m != nil {
+		return m.MessageId
+	}
+	return ""
+}
+
+func (m *SendName_Request) GetDelivery() Delivery {
+	if m != nil {
+		return m.Delivery
+	}
+	return Delivery_AtMostOnce
+}
+
+func (m *SendName_Request) GetEnvelope() *ConnEnvelope {
+	if m != nil {
+		return m.Envelope
+	}
+	return nil
+}
+
+func (m *SendName_Request) GetToId() uint32 {
+	if m != nil {
+		return m.ToId
+	}
+	return 0
+}
+
 type SendName_Response struct {
 	HasError             bool     `protobuf:"varint,1,opt,name=has_error,json=hasError,proto3" json:"has_error,omitempty"`
 	ErrorMessage         string   `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
@@ -785,7 +962,7 @@ func (m *SendName_Response) Reset()         { *m = SendName_Response{} }
 func (m *SendName_Response) String() string { return proto.CompactTextString(m) }
 func (*SendName_Response) ProtoMessage()    {}
 func (*SendName_Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{3, 1}
+	return fileDescriptor_f401a58c1fc7ceef, []int{4, 1}
 }
 
 func (m *SendName_Response) XXX_Unmarshal(b []byte) error {
@@ -820,6 +997,163 @@ func (m *SendName_Response) GetErrorMessage() string {
 	return ""
 }
 
+type ConnEnvelope struct {
+	CorrelationId        string            `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	ReplyToNode          uint32            `protobuf:"varint,2,opt,name=reply_to_node,json=replyToNode,proto3" json:"reply_to_node,omitempty"`
+	ReplyToId            uint32            `protobuf:"varint,3,opt,name=reply_to_id,json=replyToId,proto3" json:"reply_to_id,omitempty"`
+	ReplyToName          string            `protobuf:"bytes,4,opt,name=reply_to_name,json=replyToName,proto3" json:"reply_to_name,omitempty"`
+	Headers              map[string]string `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ConnEnvelope) Reset()         { *m = ConnEnvelope{} }
+func (m *ConnEnvelope) String() string { return proto.CompactTextString(m) }
+func (*ConnEnvelope) ProtoMessage()    {}
+func (*ConnEnvelope) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{5}
+}
+
+func (m *ConnEnvelope) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnEnvelope.Unmarshal(m, b)
+}
+func (m *ConnEnvelope) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnEnvelope.Marshal(b, m, deterministic)
+}
+func (m *ConnEnvelope) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnEnvelope.Merge(m, src)
+}
+func (m *ConnEnvelope) XXX_Size() int {
+	return xxx_messageInfo_ConnEnvelope.Size(m)
+}
+func (m *ConnEnvelope) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnEnvelope.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConnEnvelope proto.InternalMessageInfo
+
+func (m *ConnEnvelope) GetCorrelationId() string {
+	if m != nil {
+		return m.CorrelationId
+	}
+	return ""
+}
+
+func (m *ConnEnvelope) GetReplyToNode() uint32 {
+	if m != nil {
+		return m.ReplyToNode
+	}
+	return 0
+}
+
+func (m *ConnEnvelope) GetReplyToId() uint32 {
+	if m != nil {
+		return m.ReplyToId
+	}
+	return 0
+}
+
+func (m *ConnEnvelope) GetReplyToName() string {
+	if m != nil {
+		return m.ReplyToName
+	}
+	return ""
+}
+
+func (m *ConnEnvelope) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+type SendAck struct {
+	SequenceIds          []uint64 `protobuf:"varint,1,rep,packed,name=sequence_ids,json=sequenceIds,proto3" json:"sequence_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SendAck) Reset()         { *m = SendAck{} }
+func (m *SendAck) String() string { return proto.CompactTextString(m) }
+func (*SendAck) ProtoMessage()    {}
+func (*SendAck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{6}
+}
+
+func (m *SendAck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SendAck.Unmarshal(m, b)
+}
+func (m *SendAck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SendAck.Marshal(b, m, deterministic)
+}
+func (m *SendAck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SendAck.Merge(m, src)
+}
+func (m *SendAck) XXX_Size() int {
+	return xxx_messageInfo_SendAck.Size(m)
+}
+func (m *SendAck) XXX_DiscardUnknown() {
+	xxx_messageInfo_SendAck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SendAck proto.InternalMessageInfo
+
+func (m *SendAck) GetSequenceIds() []uint64 {
+	if m != nil {
+		return m.SequenceIds
+	}
+	return nil
+}
+
+type Publish struct {
+	Topic                string           `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Data                 *DataContentType `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Publish) Reset()         { *m = Publish{} }
+func (m *Publish) String() string { return proto.CompactTextString(m) }
+func (*Publish) ProtoMessage()    {}
+func (*Publish) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f401a58c1fc7ceef, []int{7}
+}
+
+func (m *Publish) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Publish.Unmarshal(m, b)
+}
+func (m *Publish) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Publish.Marshal(b, m, deterministic)
+}
+func (m *Publish) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Publish.Merge(m, src)
+}
+func (m *Publish) XXX_Size() int {
+	return xxx_messageInfo_Publish.Size(m)
+}
+func (m *Publish) XXX_DiscardUnknown() {
+	xxx_messageInfo_Publish.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Publish proto.InternalMessageInfo
+
+func (m *Publish) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *Publish) GetData() *DataContentType {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 type AskName struct {
 	// Types that are valid to be assigned to Data:
 	//	*AskName_Req
@@ -834,7 +1168,7 @@ func (m *AskName) Reset()         { *m = AskName{} }
 func (m *AskName) String() string { return proto.CompactTextString(m) }
 func (*AskName) ProtoMessage()    {}
 func (*AskName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{4}
+	return fileDescriptor_f401a58c1fc7ceef, []int{8}
 }
 
 func (m *AskName) XXX_Unmarshal(b []byte) error {
@@ -906,6 +1240,7 @@ type AskName_Request struct {
 	ToName               string           `protobuf:"bytes,3,opt,name=to_name,json=toName,proto3" json:"to_name,omitempty"`
 	AskData              *DataContentType `protobuf:"bytes,4,opt,name=ask_data,json=askData,proto3" json:"ask_data,omitempty"`
 	AnswerData           *DataContentType `protobuf:"bytes,5,opt,name=answer_data,json=answerData,proto3" json:"answer_data,omitempty"`
+	Envelope             *ConnEnvelope    `protobuf:"bytes,6,opt,name=envelope,proto3" json:"envelope,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
@@ -915,7 +1250,7 @@ func (m *AskName_Request) Reset()         { *m = AskName_Request{} }
 func (m *AskName_Request) String() string { return proto.CompactTextString(m) }
 func (*AskName_Request) ProtoMessage()    {}
 func (*AskName_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{4, 0}
+	return fileDescriptor_f401a58c1fc7ceef, []int{8, 0}
 }
 
 func (m *AskName_Request) XXX_Unmarshal(b []byte) error {
@@ -971,6 +1306,13 @@ func (m *AskName_Request) GetAnswerData() *DataContentType {
 	return nil
 }
 
+func (m *AskName_Request) GetEnvelope() *ConnEnvelope {
+	if m != nil {
+		return m.Envelope
+	}
+	return nil
+}
+
 type AskName_Response struct {
 	HasError             bool             `protobuf:"varint,1,opt,name=has_error,json=hasError,proto3" json:"has_error,omitempty"`
 	ErrorMessage         string           `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
@@ -984,7 +1326,7 @@ func (m *AskName_Response) Reset()         { *m = AskName_Response{} }
 func (m *AskName_Response) String() string { return proto.CompactTextString(m) }
 func (*AskName_Response) ProtoMessage()    {}
 func (*AskName_Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{4, 1}
+	return fileDescriptor_f401a58c1fc7ceef, []int{8, 1}
 }
 
 func (m *AskName_Response) XXX_Unmarshal(b []byte) error {
@@ -1040,7 +1382,7 @@ func (m *GetName) Reset()         { *m = GetName{} }
 func (m *GetName) String() string { return proto.CompactTextString(m) }
 func (*GetName) ProtoMessage()    {}
 func (*GetName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{5}
+	return fileDescriptor_f401a58c1fc7ceef, []int{9}
 }
 
 func (m *GetName) XXX_Unmarshal(b []byte) error {
@@ -1117,7 +1459,7 @@ func (m *GetName_Request) Reset()         { *m = GetName_Request{} }
 func (m *GetName_Request) String() string { return proto.CompactTextString(m) }
 func (*GetName_Request) ProtoMessage()    {}
 func (*GetName_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{5, 0}
+	return fileDescriptor_f401a58c1fc7ceef, []int{9, 0}
 }
 
 func (m *GetName_Request) XXX_Unmarshal(b []byte) error {
@@ -1158,7 +1500,7 @@ func (m *GetName_Response) Reset()         { *m = GetName_Response{} }
 func (m *GetName_Response) String() string { return proto.CompactTextString(m) }
 func (*GetName_Response) ProtoMessage()    {}
 func (*GetName_Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f401a58c1fc7ceef, []int{5, 1}
+	return fileDescriptor_f401a58c1fc7ceef, []int{9, 1}
 }
 
 func (m *GetName_Response) XXX_Unmarshal(b []byte) error {
@@ -1204,14 +1546,21 @@ func init() {
 	proto.RegisterEnum("actor.ControlType", ControlType_name, ControlType_value)
 	proto.RegisterEnum("actor.Direction", Direction_name, Direction_value)
 	proto.RegisterEnum("actor.DataType", DataType_name, DataType_value)
+	proto.RegisterEnum("actor.AckMode", AckMode_name, AckMode_value)
+	proto.RegisterEnum("actor.Delivery", Delivery_name, Delivery_value)
 	proto.RegisterType((*ConnMessage)(nil), "actor.ConnMessage")
 	proto.RegisterType((*Auth)(nil), "actor.Auth")
 	proto.RegisterType((*Auth_Request)(nil), "actor.Auth.Request")
 	proto.RegisterType((*Auth_Response)(nil), "actor.Auth.Response")
+	proto.RegisterType((*Heartbeat)(nil), "actor.Heartbeat")
 	proto.RegisterType((*DataContentType)(nil), "actor.DataContentType")
 	proto.RegisterType((*SendName)(nil), "actor.SendName")
 	proto.RegisterType((*SendName_Request)(nil), "actor.SendName.Request")
 	proto.RegisterType((*SendName_Response)(nil), "actor.SendName.Response")
+	proto.RegisterType((*ConnEnvelope)(nil), "actor.ConnEnvelope")
+	proto.RegisterMapType((map[string]string)(nil), "actor.ConnEnvelope.HeadersEntry")
+	proto.RegisterType((*SendAck)(nil), "actor.SendAck")
+	proto.RegisterType((*Publish)(nil), "actor.Publish")
 	proto.RegisterType((*AskName)(nil), "actor.AskName")
 	proto.RegisterType((*AskName_Request)(nil), "actor.AskName.Request")
 	proto.RegisterType((*AskName_Response)(nil), "actor.AskName.Response")
@@ -1223,67 +1572,91 @@ func init() {
 func init() { proto.RegisterFile("conn.proto", fileDescriptor_f401a58c1fc7ceef) }
 
 var fileDescriptor_f401a58c1fc7ceef = []byte{
-	// 979 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x95, 0xcd, 0x8e, 0xe3, 0x44,
-	0x10, 0xc7, 0xe3, 0xd8, 0x89, 0xed, 0xca, 0xcc, 0x6c, 0xd3, 0x8b, 0x36, 0xd9, 0x2c, 0x88, 0x30,
-	0x23, 0x2d, 0xa3, 0x00, 0x59, 0x4d, 0x26, 0x32, 0x7b, 0x9d, 0x64, 0x81, 0x44, 0x62, 0x57, 0xe0,
-	0x61, 0x6e, 0x48, 0x91, 0x13, 0x77, 0x3e, 0x34, 0x49, 0x77, 0xd6, 0xdd, 0xd1, 0x90, 0x07, 0xe0,
-	0xc8, 0x13, 0x21, 0x71, 0xe2, 0xca, 0x43, 0x70, 0xe4, 0xc4, 0x81, 0x17, 0x40, 0xd5, 0x6e, 0x3b,
-	0x1f, 0x03, 0x68, 0x0e, 0x70, 0xab, 0xae, 0xfa, 0xb7, 0xbb, 0xba, 0x7e, 0xd5, 0x65, 0x80, 0xb1,
-	0xe0, 0xbc, 0xb5, 0x4a, 0x84, 0x12, 0xb4, 0x14, 0x8d, 0x95, 0x48, 0xea, 0x4f, 0xa7, 0x42, 0x4c,
-	0x17, 0xec, 0x85, 0x76, 0x8e, 0xd6, 0x93, 0x17, 0x11, 0xdf, 0xa4, 0x8a, 0xd3, 0x9f, 0x8b, 0x50,
-	0xe9, 0x09, 0xce, 0x5f, 0x33, 0x29, 0xa3, 0x29, 0xa3, 0x1f, 0x40, 0x45, 0xb2, 0xb7, 0x6b, 0xc6,
-	0xc7, 0x6c, 0x38, 0x8f, 0x6b, 0x56, 0xc3, 0x3a, 0x77, 0x42, 0xc8, 0x5c, 0x83, 0x98, 0x3e, 0x07,
-	0x47, 0x6d, 0x56, 0xac, 0x56, 0x6c, 0x58, 0xe7, 0x27, 0x6d, 0xda, 0xd2, 0x27, 0xb4, 0x7a, 0x82,
-	0xab, 0x44, 0x2c, 0xbe, 0xdd, 0xac, 0x58, 0xa8, 0xe3, 0xb4, 0x05, 0x7e, 0x3c, 0x4f, 0xd8, 0x58,
-	0xcd, 0x05, 0xaf, 0xd9, 0x5a, 0x4c, 0x8c, 0xf8, 0x55, 0xe6, 0x0f, 0xb7, 0x12, 0xfa, 0x21, 0x38,
-	0xd1, 0x5a, 0xcd, 0x6a, 0x4e, 0xc3, 0x3a, 0xaf, 0xb4, 0x2b, 0x46, 0x7a, 0xb5, 0x56, 0xb3, 0x7e,
-	0x21, 0xd4, 0x21, 0xfa, 0x31, 0x78, 0x53, 0xa6, 0x86, 0x3c, 0x5a, 0xb2, 0x5a, 0x49, 0xcb, 0x4e,
-	0x8c, 0xec, 0x4b, 0xa6, 0xde, 0x44, 0x4b, 0xd6, 0x2f, 0x84, 0xee, 0x34, 0x35, 0xf1, 0x7c, 0xc9,
-	0x78, 0x9c, 0xaa, 0xcb, 0x5a, 0xfd, 0xc8, 0xa8, 0xaf, 0x19, 0x8f, 0x8d, 0xdc, 0x93, 0xc6, 0xc6,
-	0x8f, 0x47, 0xf2, 0x36, 0x95, 0xbb, 0x7b, 0x1f, 0xbf, 0x92, 0xb7, 0xd9, 0xc7, 0xa3, 0xd4, 0xec,
-	0xfa, 0xe0, 0x8e, 0x05, 0x57, 0x8c, 0xab, 0xd3, 0x3f, 0x2c, 0x70, 0x30, 0x4b, 0xfa, 0x11, 0xd8,
-	0x09, 0x7b, 0xab, 0x2b, 0x56, 0x69, 0x3f, 0xde, 0xc9, 0xbf, 0x15, 0x62, 0xf5, 0xa4, 0xea, 0x17,
-	0x42, 0x54, 0xd0, 0x26, 0x38, 0x09, 0x93, 0x2b, 0x5d, 0xc1, 0x4a, 0xfb, 0xdd, 0x7d, 0xa5, 0x5c,
-	0x09, 0x2e, 0xf1, 0x2c, 0xad, 0xa9, 0x33, 0x70, 0xcd, 0x6e, 0xda, 0x80, 0xa3, 0x49, 0x22, 0x96,
-	0x43, 0x2e, 0xe2, 0x1c, 0xcd, 0x71, 0x08, 0xe8, 0x7b, 0x23, 0x62, 0x44, 0xf3, 0x1e, 0x80, 0x12,
-	0x79, 0xbc, 0xa8, 0xe3, 0x9e, 0x12, 0x26, 0x5a, 0x07, 0x6f, 0x15, 0x49, 0x79, 0x27, 0x92, 0x58,
-	0xf3, 0xf0, 0xc3, 0x7c, 0x5d, 0x3f, 0x03, 0x2f, 0x3b, 0x9a, 0x56, 0xc1, 0x9d, 0xcb, 0xa1, 0x66,
-	0x81, 0x47, 0x78, 0x61, 0x79, 0x2e, 0x31, 0xb9, 0x6e, 0x19, 0x9c, 0x38, 0x52, 0xd1, 0xe9, 0x6f,
-	0x16, 0x3c, 0x7a, 0x15, 0xa9, 0xa8, 0x97, 0x56, 0x00, 0x99, 0xd3, 0x33, 0xd3, 0x15, 0x96, 0x06,
-	0x9d, 0x15, 0x1a, 0x55, 0x3b, 0x2d, 0xf1, 0x09, 0x94, 0x74, 0xd3, 0xe5, 0x37, 0x4f, 0xdb, 0xb2,
-	0x95, 0xb5, 0x65, 0xeb, 0x8a, 0x6f, 0xfa, 0x85, 0x30, 0x15, 0xd1, 0x13, 0xb0, 0x46, 0x3a, 0x51,
-	0xaf, 0x5f, 0x08, 0xad, 0x11, 0x25, 0x50, 0x1c, 0x49, 0xdd, 0x1e, 0x47, 0xfd, 0x42, 0x58, 0x1c,
-	0x49, 0x4a, 0xc1, 0x96, 0x2a, 0xd1, 0xad, 0xe0, 0x63, 0x71, 0xa5, 0x4a, 0xd0, 0x37, 0x0f, 0x3a,
-	0x1a, 0xb8, 0x8d, 0xbe, 0x79, 0xd0, 0x41, 0xdf, 0x3a, 0xe8, 0x68, 0xaa, 0x0e, 0xfa, 0xd6, 0xa9,
-	0x6f, 0x12, 0x74, 0x6a, 0x5e, 0xc3, 0x3a, 0xb7, 0xd0, 0x37, 0x09, 0x3a, 0xbb, 0x54, 0x7f, 0x2d,
-	0x82, 0x77, 0xbd, 0x6d, 0x8d, 0x1d, 0xb2, 0xd5, 0x83, 0x26, 0x3a, 0xa4, 0xdb, 0xda, 0xa3, 0x5b,
-	0xbb, 0xaf, 0x3e, 0x20, 0xfc, 0xa3, 0xb5, 0x45, 0x5c, 0x05, 0x57, 0x23, 0xce, 0xe9, 0x96, 0x71,
-	0x39, 0x88, 0xe9, 0x33, 0xf0, 0x53, 0xf6, 0xd8, 0x9d, 0xc5, 0x14, 0x9e, 0x06, 0x8f, 0xe9, 0x55,
-	0xc1, 0x45, 0xec, 0x18, 0x4a, 0xb9, 0x96, 0x95, 0xd0, 0x81, 0x4b, 0xf3, 0x04, 0x90, 0x9a, 0x79,
-	0x57, 0x4f, 0x76, 0xc8, 0xec, 0xf0, 0x4b, 0xdf, 0x01, 0x3a, 0xeb, 0x5f, 0xed, 0xb4, 0xc2, 0x33,
-	0xf0, 0x67, 0x91, 0x1c, 0xb2, 0x24, 0x11, 0x89, 0x69, 0x06, 0x6f, 0x16, 0xc9, 0xcf, 0x71, 0x4d,
-	0xcf, 0xe0, 0x58, 0x07, 0x86, 0xcb, 0x74, 0x74, 0x98, 0xbc, 0x8e, 0xb4, 0xd3, 0x8c, 0x93, 0xbc,
-	0x67, 0x7e, 0xb2, 0xc1, 0x35, 0xef, 0x88, 0x36, 0x77, 0xcb, 0xf9, 0x64, 0xff, 0x91, 0x1d, 0x56,
-	0xf3, 0xd3, 0xbd, 0x6a, 0x56, 0xef, 0x89, 0x0f, 0x8a, 0xf9, 0xcb, 0xff, 0x57, 0xcc, 0x8b, 0x74,
-	0x3e, 0x3c, 0xa0, 0x96, 0x38, 0x25, 0xd0, 0x47, 0x3f, 0x83, 0x4a, 0xc4, 0xe5, 0x1d, 0x4b, 0xd2,
-	0x5d, 0xa5, 0x7f, 0xdd, 0x05, 0xa9, 0x54, 0x33, 0xf8, 0xc1, 0xfa, 0x2f, 0x21, 0x1c, 0xe6, 0x61,
-	0x3f, 0x34, 0x8f, 0x9c, 0xde, 0xef, 0x16, 0xb8, 0x66, 0xc4, 0xfe, 0x3d, 0x3d, 0x13, 0x7c, 0x18,
-	0xbd, 0xad, 0xf8, 0x80, 0xde, 0xfb, 0x5b, 0x78, 0x14, 0x1c, 0xcd, 0xc0, 0xd2, 0xd7, 0xd1, 0x76,
-	0xfd, 0xbb, 0x9d, 0xa2, 0x10, 0xb0, 0x67, 0x91, 0x34, 0xe5, 0x40, 0x93, 0x3e, 0x05, 0x4f, 0x7f,
-	0x7e, 0x3b, 0xfa, 0x5c, 0xbd, 0x1e, 0xc4, 0xf7, 0x8b, 0x64, 0xff, 0x73, 0xa7, 0x36, 0xbf, 0xd1,
-	0xff, 0xc3, 0xec, 0x67, 0x46, 0x8f, 0xc0, 0xeb, 0xdd, 0xf0, 0x5b, 0x2e, 0xee, 0x38, 0x29, 0x50,
-	0x1f, 0x4a, 0x3d, 0x9c, 0x85, 0xa4, 0x48, 0x8f, 0xc1, 0xef, 0x65, 0xaf, 0x9a, 0xd8, 0x5a, 0x67,
-	0xda, 0x92, 0x38, 0x7a, 0x65, 0xae, 0x49, 0x4a, 0xcd, 0xe7, 0xe0, 0xe7, 0xbf, 0x3c, 0x5a, 0xc9,
-	0x2f, 0x49, 0x0a, 0xa8, 0xcb, 0xae, 0x44, 0xac, 0xe6, 0x9f, 0x16, 0x78, 0xd9, 0xc8, 0xc4, 0xd0,
-	0xd7, 0x38, 0x07, 0xbb, 0xeb, 0x09, 0x29, 0x50, 0x0f, 0x9c, 0xae, 0x10, 0x0b, 0x62, 0x61, 0x0a,
-	0xdd, 0x8d, 0x62, 0x92, 0x14, 0x29, 0x40, 0xf9, 0x5a, 0x25, 0x73, 0x3e, 0x25, 0x36, 0x75, 0xc1,
-	0x1e, 0x70, 0x45, 0x1c, 0x54, 0x0e, 0xb8, 0x7a, 0x49, 0x4a, 0xa8, 0x1c, 0x70, 0x75, 0x11, 0x90,
-	0xb2, 0x31, 0x2f, 0xdb, 0xc4, 0x35, 0x66, 0xd0, 0x21, 0x1e, 0x4a, 0x6f, 0x70, 0x93, 0x8f, 0xce,
-	0x1b, 0xbd, 0x0b, 0xf0, 0xa3, 0x37, 0xe9, 0xb6, 0x4a, 0x66, 0x5f, 0xb6, 0xc9, 0x51, 0x66, 0x07,
-	0x1d, 0x72, 0x8c, 0x77, 0xf8, 0x62, 0x21, 0x22, 0x0c, 0x9c, 0xe4, 0x8b, 0xa0, 0x43, 0x1e, 0xe9,
-	0xaa, 0x88, 0xe5, 0x6a, 0xc1, 0xbe, 0x0f, 0x3a, 0x84, 0xd0, 0x13, 0x00, 0xb3, 0xbc, 0x68, 0xbf,
-	0x24, 0xef, 0xe0, 0x39, 0xd7, 0x8b, 0xf9, 0x98, 0x11, 0x8a, 0x09, 0xbf, 0x8e, 0x56, 0xe4, 0xf1,
-	0xa8, 0xac, 0xc7, 0xfd, 0xe5, 0x5f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xf3, 0x6a, 0x5c, 0x08, 0xb8,
-	0x08, 0x00, 0x00,
+	// 1372 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
+	0x17, 0x15, 0x45, 0x4a, 0x24, 0x2f, 0x65, 0x7b, 0x30, 0x09, 0x62, 0x45, 0x49, 0xbe, 0xcf, 0x71,
+	0xd0, 0xd4, 0x51, 0x53, 0xa5, 0xb1, 0x05, 0x35, 0x08, 0xba, 0x91, 0x1d, 0xb7, 0x12, 0x9a, 0xa4,
+	0x01, 0x13, 0xef, 0x0a, 0x08, 0x23, 0x72, 0x6c, 0x09, 0x92, 0x38, 0x0a, 0x67, 0x94, 0x54, 0x8b,
+	0x2e, 0xbb, 0xe9, 0xbe, 0xef, 0xd3, 0x6d, 0xfb, 0x16, 0x5d, 0x16, 0x28, 0xd0, 0x55, 0xf7, 0xc5,
+	0xfc, 0x90, 0xa2, 0x94, 0x34, 0xf0, 0xa2, 0xdd, 0xcd, 0x9c, 0x7b, 0x38, 0x3f, 0xf7, 0x9e, 0x33,
+	0x97, 0x00, 0x11, 0x4b, 0x92, 0xd6, 0x3c, 0x65, 0x82, 0xe1, 0x0a, 0x89, 0x04, 0x4b, 0x1b, 0xd7,
+	0x2f, 0x18, 0xbb, 0x98, 0xd2, 0x07, 0x0a, 0x1c, 0x2e, 0xce, 0x1f, 0x90, 0x64, 0xa9, 0x19, 0xfb,
+	0xbf, 0xd8, 0x10, 0x9c, 0xb0, 0x24, 0x79, 0x46, 0x39, 0x27, 0x17, 0x14, 0xff, 0x1f, 0x02, 0x4e,
+	0x5f, 0x2f, 0x68, 0x12, 0xd1, 0xc1, 0x38, 0xae, 0x5b, 0x7b, 0xd6, 0x81, 0x13, 0x42, 0x06, 0xf5,
+	0x63, 0x7c, 0x17, 0x1c, 0xb1, 0x9c, 0xd3, 0x7a, 0x79, 0xcf, 0x3a, 0xd8, 0x3e, 0xc4, 0x2d, 0xb5,
+	0x43, 0xeb, 0x84, 0x25, 0x22, 0x65, 0xd3, 0x57, 0xcb, 0x39, 0x0d, 0x55, 0x1c, 0xb7, 0xc0, 0x8f,
+	0xc7, 0x29, 0x8d, 0xc4, 0x98, 0x25, 0x75, 0x5b, 0x91, 0x91, 0x21, 0x3f, 0xc9, 0xf0, 0x70, 0x45,
+	0xc1, 0xb7, 0xc1, 0x21, 0x0b, 0x31, 0xaa, 0x3b, 0x7b, 0xd6, 0x41, 0x70, 0x18, 0x18, 0x6a, 0x77,
+	0x21, 0x46, 0xbd, 0x52, 0xa8, 0x42, 0xf8, 0x13, 0xf0, 0x2e, 0xa8, 0x18, 0x24, 0x64, 0x46, 0xeb,
+	0x15, 0x45, 0xdb, 0x36, 0xb4, 0xaf, 0xa8, 0x78, 0x4e, 0x66, 0xb4, 0x57, 0x0a, 0xdd, 0x0b, 0x3d,
+	0x94, 0xfb, 0x73, 0x9a, 0xc4, 0x9a, 0x5d, 0x55, 0xec, 0x1d, 0xc3, 0x7e, 0x49, 0x93, 0xd8, 0xd0,
+	0x3d, 0x6e, 0xc6, 0x72, 0x71, 0xc2, 0x27, 0x9a, 0xee, 0xae, 0x2d, 0xde, 0xe5, 0x93, 0x6c, 0x71,
+	0xa2, 0x87, 0xf8, 0x33, 0xf0, 0x47, 0x94, 0xa4, 0x62, 0x48, 0x89, 0xa8, 0x7b, 0x8a, 0x9d, 0x5d,
+	0xae, 0x97, 0xe1, 0xbd, 0x52, 0xb8, 0x22, 0xc9, 0xe5, 0xd5, 0x71, 0x48, 0x34, 0xa9, 0xfb, 0x6b,
+	0xcb, 0xcb, 0xd3, 0x74, 0xa3, 0x89, 0x5c, 0x9e, 0xeb, 0x21, 0x6e, 0x82, 0x3b, 0x5f, 0x0c, 0xa7,
+	0x63, 0x3e, 0xaa, 0xc3, 0x1a, 0xf7, 0x85, 0x46, 0x25, 0xd7, 0x10, 0x8e, 0x7d, 0x70, 0x23, 0x96,
+	0x08, 0x9a, 0x88, 0xfd, 0x3f, 0x2d, 0x70, 0x64, 0xc2, 0xf0, 0xc7, 0x60, 0xa7, 0xf4, 0xb5, 0x2a,
+	0x5e, 0x70, 0x78, 0xa5, 0x90, 0xca, 0x56, 0x28, 0x0b, 0xc9, 0xe5, 0xd9, 0x24, 0x03, 0x37, 0xc1,
+	0x49, 0x29, 0x9f, 0xab, 0x62, 0x06, 0x87, 0x57, 0xd7, 0x99, 0x7c, 0xce, 0x12, 0x2e, 0xaf, 0xad,
+	0x38, 0x0d, 0x0a, 0xae, 0xf9, 0x1a, 0xef, 0x41, 0xed, 0x3c, 0x65, 0xb3, 0x41, 0xc2, 0xe2, 0x5c,
+	0x25, 0x5b, 0x21, 0x48, 0xec, 0x39, 0x8b, 0xa5, 0x4a, 0x6e, 0x02, 0x08, 0x96, 0xc7, 0xcb, 0x2a,
+	0xee, 0x09, 0x66, 0xa2, 0x0d, 0xf0, 0xe6, 0x84, 0xf3, 0xb7, 0x2c, 0x8d, 0x95, 0x34, 0xfc, 0x30,
+	0x9f, 0x37, 0xee, 0x80, 0x97, 0x6d, 0x8d, 0x77, 0xc1, 0x1d, 0xf3, 0x81, 0x92, 0x85, 0xdc, 0xc2,
+	0x0b, 0xab, 0x63, 0x2e, 0x0f, 0x77, 0x5c, 0x05, 0x27, 0x26, 0x82, 0xec, 0xdf, 0x03, 0x3f, 0xcf,
+	0x37, 0xbe, 0x09, 0xbe, 0x18, 0xcf, 0x28, 0x17, 0x64, 0x36, 0x57, 0x7c, 0x3b, 0x5c, 0x01, 0xfb,
+	0xbf, 0x59, 0xb0, 0xf3, 0x84, 0x08, 0x72, 0xa2, 0x93, 0x25, 0x95, 0x8a, 0xef, 0x18, 0x2d, 0x5b,
+	0x4a, 0x9e, 0x99, 0x3c, 0x24, 0xab, 0x20, 0xe4, 0xfb, 0x50, 0x51, 0x56, 0xc9, 0x93, 0xa4, 0xcd,
+	0xd4, 0xca, 0xcc, 0xd4, 0xea, 0x26, 0xcb, 0x5e, 0x29, 0xd4, 0x24, 0xbc, 0x0d, 0xd6, 0x50, 0xdd,
+	0xc9, 0xeb, 0x95, 0x42, 0x6b, 0x88, 0x11, 0x94, 0x87, 0x5c, 0x89, 0xba, 0xd6, 0x2b, 0x85, 0xe5,
+	0x21, 0xc7, 0x18, 0x6c, 0x2e, 0x52, 0x25, 0x60, 0x5f, 0xd6, 0x81, 0x8b, 0x54, 0x62, 0xe3, 0x4e,
+	0x5b, 0xc9, 0xd4, 0x96, 0xd8, 0xb8, 0xd3, 0x96, 0xd8, 0xa2, 0xd3, 0x56, 0x5a, 0x74, 0x24, 0xb6,
+	0xd0, 0xd8, 0x79, 0xa7, 0xad, 0x14, 0x67, 0x49, 0xec, 0xbc, 0xd3, 0x2e, 0x0a, 0xe0, 0x47, 0x07,
+	0xbc, 0x97, 0x2b, 0x41, 0x17, 0x44, 0xb0, 0xbb, 0x21, 0xfd, 0x4d, 0x21, 0xb4, 0xd6, 0x84, 0x50,
+	0x7f, 0x97, 0xbd, 0x21, 0x86, 0x5f, 0xcb, 0x2b, 0x35, 0xec, 0x82, 0xab, 0xd4, 0x90, 0x0b, 0xa1,
+	0x2a, 0xa7, 0xfd, 0x18, 0xdf, 0x00, 0x5f, 0x05, 0x94, 0xa7, 0xca, 0xba, 0xce, 0x4a, 0x23, 0xf2,
+	0x78, 0xbb, 0xe0, 0x0a, 0xa6, 0x43, 0x5a, 0x02, 0x55, 0xc1, 0x54, 0xe0, 0xc8, 0x18, 0x57, 0x16,
+	0xd8, 0xbc, 0x06, 0xd7, 0x0a, 0x95, 0x29, 0xd4, 0x4f, 0xbb, 0x57, 0x82, 0xf8, 0x1e, 0x78, 0x24,
+	0x9a, 0x0c, 0x66, 0x2c, 0xd6, 0x4f, 0xc3, 0xf6, 0xca, 0xbd, 0xd1, 0xe4, 0x19, 0x8b, 0x69, 0xe8,
+	0x12, 0x3d, 0xc0, 0xb7, 0x00, 0x66, 0xfa, 0xb1, 0x93, 0x27, 0xae, 0xaa, 0xbd, 0x7d, 0x83, 0xf4,
+	0x63, 0x69, 0xd4, 0x98, 0x4e, 0xc7, 0x6f, 0x68, 0xba, 0xac, 0xbb, 0xeb, 0xba, 0x30, 0x70, 0x98,
+	0x13, 0xf0, 0x03, 0xf0, 0x68, 0xf2, 0x86, 0x4e, 0xd9, 0x9c, 0xd6, 0xbd, 0x35, 0xb7, 0xc9, 0x37,
+	0xf5, 0xd4, 0x84, 0xc2, 0x9c, 0x84, 0xaf, 0x40, 0x45, 0x30, 0xb9, 0xaf, 0xaf, 0x32, 0xe5, 0x08,
+	0xd6, 0x8f, 0x1b, 0x4f, 0x0b, 0x92, 0xbf, 0x01, 0xfe, 0x88, 0xf0, 0x01, 0x4d, 0x53, 0x96, 0x1a,
+	0xd1, 0x7b, 0x23, 0xc2, 0x4f, 0xe5, 0x1c, 0xdf, 0x81, 0x2d, 0x15, 0x18, 0x98, 0xe3, 0x9a, 0xa4,
+	0xd6, 0x14, 0x68, 0x5e, 0xf0, 0xdc, 0x1b, 0x3f, 0x95, 0xa1, 0x56, 0x3c, 0x05, 0xfe, 0x08, 0xb6,
+	0x23, 0x96, 0xa6, 0x74, 0x4a, 0xe4, 0x83, 0x9b, 0x95, 0xcb, 0x0f, 0xb7, 0x0a, 0x68, 0x3f, 0xc6,
+	0xfb, 0xb0, 0x95, 0xd2, 0xf9, 0x74, 0x39, 0x30, 0x06, 0x36, 0xee, 0x0d, 0x14, 0xf8, 0x4a, 0x59,
+	0x18, 0xff, 0x0f, 0x82, 0x9c, 0x33, 0xd6, 0x1e, 0xde, 0x0a, 0x7d, 0xc3, 0xd8, 0x5c, 0x43, 0x96,
+	0xd8, 0x51, 0x3b, 0xe5, 0x6b, 0xc8, 0x3a, 0x3f, 0x06, 0x77, 0x44, 0x49, 0x4c, 0x53, 0x5e, 0xaf,
+	0xec, 0xd9, 0x07, 0xc1, 0xe1, 0xde, 0x7b, 0x52, 0xd7, 0xea, 0x69, 0xca, 0x69, 0x22, 0xd2, 0x65,
+	0x98, 0x7d, 0xd0, 0x78, 0x0c, 0xb5, 0x62, 0x00, 0x23, 0xb0, 0x27, 0x74, 0x69, 0xee, 0x23, 0x87,
+	0xf8, 0x2a, 0x54, 0xde, 0x90, 0xe9, 0x22, 0x4b, 0x91, 0x9e, 0x3c, 0x2e, 0x3f, 0xb2, 0xf6, 0xef,
+	0x83, 0x6b, 0x9e, 0x5c, 0x7c, 0x1b, 0x6a, 0x85, 0x66, 0xc7, 0xeb, 0xd6, 0x9e, 0x7d, 0xe0, 0x84,
+	0xc1, 0xaa, 0xdb, 0xf1, 0xfd, 0xaf, 0xc1, 0x35, 0x8f, 0xae, 0x5c, 0x52, 0xb0, 0xf9, 0x38, 0x32,
+	0xdb, 0xe8, 0x09, 0x6e, 0xea, 0x74, 0xd7, 0xcb, 0x1f, 0x54, 0xaa, 0x2e, 0xc9, 0x1f, 0x36, 0xb8,
+	0xa6, 0x9b, 0xe0, 0x66, 0xd1, 0x9e, 0xd7, 0xd6, 0x5b, 0xcd, 0xa6, 0x3b, 0x3f, 0x5d, 0x73, 0xe7,
+	0xee, 0x3b, 0xe4, 0x0d, 0x73, 0xfe, 0x65, 0xfd, 0x67, 0xe6, 0x7c, 0xa8, 0xbb, 0xe4, 0x25, 0xbc,
+	0x29, 0x7b, 0xa5, 0xc4, 0xf0, 0xe7, 0x10, 0x90, 0x84, 0xbf, 0xa5, 0xa9, 0xfe, 0xaa, 0xf2, 0xc1,
+	0xaf, 0x40, 0x53, 0xd5, 0x87, 0x45, 0x73, 0x55, 0x2f, 0x61, 0xae, 0xc6, 0x0f, 0xd6, 0xbf, 0x69,
+	0xa4, 0xcd, 0x83, 0xdb, 0x97, 0x3d, 0x78, 0xee, 0xc0, 0xdf, 0x2d, 0x70, 0xcd, 0x9f, 0xc9, 0xfb,
+	0xcb, 0x6d, 0x82, 0x97, 0x2b, 0xf7, 0x8a, 0xbc, 0x51, 0xee, 0x5b, 0xab, 0x6a, 0x63, 0x70, 0x54,
+	0xd1, 0xb4, 0x42, 0xd5, 0xb8, 0xf1, 0x6d, 0x21, 0x29, 0x08, 0xec, 0x11, 0xe1, 0x26, 0x1d, 0x72,
+	0x88, 0xaf, 0xcb, 0x87, 0x53, 0xb0, 0x74, 0xd5, 0xa6, 0x5d, 0x35, 0xef, 0xc7, 0xef, 0x26, 0xc9,
+	0xfe, 0xe7, 0xd7, 0xa6, 0xf9, 0x3d, 0x04, 0x85, 0x7f, 0x40, 0x5c, 0x03, 0xef, 0xe4, 0x2c, 0x99,
+	0x24, 0xec, 0x6d, 0x82, 0x4a, 0xd8, 0x87, 0xca, 0x89, 0xec, 0xdb, 0xa8, 0x8c, 0xb7, 0xc0, 0x3f,
+	0xc9, 0xda, 0x0a, 0xb2, 0x15, 0xcf, 0xe8, 0x18, 0x39, 0x6a, 0x66, 0xae, 0x89, 0x2a, 0x78, 0x1b,
+	0xe0, 0x24, 0xef, 0xee, 0xa8, 0xaa, 0xa2, 0xc6, 0xb9, 0xc8, 0x55, 0x33, 0xe3, 0x4c, 0xe4, 0x35,
+	0xef, 0x82, 0x9f, 0xff, 0x55, 0xe2, 0x20, 0x4f, 0x08, 0x2a, 0xe1, 0xda, 0xea, 0xfa, 0xc8, 0x6a,
+	0xfe, 0x6c, 0x81, 0x97, 0xf5, 0x77, 0x19, 0x7a, 0x21, 0x9b, 0xf6, 0xf1, 0xe2, 0x1c, 0x95, 0xb0,
+	0x07, 0xce, 0x31, 0x63, 0x53, 0x64, 0xc9, 0xe3, 0x1e, 0x2f, 0x05, 0xe5, 0xa8, 0x8c, 0x01, 0xaa,
+	0x2f, 0x45, 0x3a, 0x4e, 0x2e, 0x90, 0x8d, 0x5d, 0xb0, 0xfb, 0x89, 0x40, 0x8e, 0x64, 0xf6, 0x13,
+	0xf1, 0x08, 0x55, 0x24, 0xb3, 0x9f, 0x88, 0x87, 0x1d, 0x54, 0x35, 0xc3, 0xa3, 0x43, 0xe4, 0x9a,
+	0x61, 0xa7, 0x8d, 0x3c, 0x49, 0x3d, 0x93, 0x1f, 0xf9, 0x12, 0x3c, 0x53, 0x5f, 0x81, 0x5c, 0xf4,
+	0x4c, 0x7f, 0x16, 0x64, 0xe3, 0xa3, 0x43, 0x54, 0xcb, 0xc6, 0x9d, 0x36, 0xda, 0x92, 0x77, 0xf8,
+	0x72, 0xca, 0x88, 0x0c, 0x6c, 0xe7, 0x93, 0x4e, 0x1b, 0xed, 0x34, 0x1f, 0x82, 0x6b, 0x7a, 0x9a,
+	0xc4, 0xbb, 0xd1, 0xe4, 0x94, 0x44, 0x23, 0x54, 0x32, 0x93, 0xe7, 0x2c, 0xa1, 0xc8, 0x92, 0x57,
+	0xeb, 0x46, 0x93, 0x63, 0x22, 0xa2, 0x11, 0x2a, 0x37, 0xbf, 0x00, 0x2f, 0x6b, 0x5e, 0x32, 0xab,
+	0x5d, 0xf1, 0x8c, 0x71, 0xf1, 0x4d, 0x12, 0x51, 0x54, 0xc2, 0x3b, 0x10, 0x74, 0xc5, 0x53, 0x4a,
+	0x0c, 0x60, 0x49, 0xe0, 0xf4, 0x3b, 0x12, 0x89, 0xe9, 0x52, 0x01, 0xe5, 0x61, 0x55, 0xfd, 0xd9,
+	0x1c, 0xfd, 0x3d, 0x00, 0x8d, 0x40, 0x39, 0xb5, 0x59, 0x0c, 0x00, 0x00,
 }
@@ -362,7 +362,7 @@ func Test_inReply_reply(t *testing.T) {
 
 func Test_outNode_auth(t *testing.T) {
 	type fields struct {
-		ready   bool
+		ready   int32
 		global  *remoteManager
 		nodeId  uint32
 		nw      Network
@@ -410,7 +410,7 @@ func Test_outNode_auth(t *testing.T) {
 
 func Test_outNode_close(t *testing.T) {
 	type fields struct {
-		ready   bool
+		ready   int32
 		global  *remoteManager
 		nodeId  uint32
 		nw      Network
@@ -449,7 +449,7 @@ func Test_outNode_close(t *testing.T) {
 
 func Test_outNode_dial(t *testing.T) {
 	type fields struct {
-		ready   bool
+		ready   int32
 		global  *remoteManager
 		nodeId  uint32
 		nw      Network
@@ -496,7 +496,7 @@ func Test_outNode_dial(t *testing.T) {
 
 func Test_outNode_loop(t *testing.T) {
 	type fields struct {
-		ready   bool
+		ready   int32
 		global  *remoteManager
 		nodeId  uint32
 		nw      Network
@@ -535,7 +535,7 @@ func Test_outNode_loop(t *testing.T) {
 
 func Test_outNode_send(t *testing.T) {
 	type fields struct {
-		ready   bool
+		ready   int32
 		global  *remoteManager
 		nodeId  uint32
 		nw      Network
@@ -0,0 +1,115 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import "time"
+
+// Well-known headers of Envelope, any other key is a custom header.
+const (
+	HeaderTraceId  = "trace-id"
+	HeaderTenantId = "tenant-id"
+	// Time in RFC 3339 format with nanoseconds, message which has not been handled
+	// before its deadline is a dead letter, and ask of it is answered with
+	// ErrDeadlineExceeded.
+	HeaderDeadline = "deadline"
+)
+
+// Set header of envelope, returns the envelope.
+func (e *Envelope) SetHeader(key, value string) *Envelope {
+	if e.Headers == nil {
+		e.Headers = map[string]string{}
+	}
+	e.Headers[key] = value
+	return e
+}
+
+// Set deadline header of envelope, returns the envelope.
+func (e *Envelope) SetDeadline(deadline time.Time) *Envelope {
+	return e.SetHeader(HeaderDeadline, deadline.UTC().Format(time.RFC3339Nano))
+}
+
+//
+// Message Context
+//
+
+// Metadata of the message being handled, see LocalRef.Context.
+type MessageContext struct {
+	Sender        Ref
+	CorrelationId string
+	ReplyTo       Ref
+	Headers       map[string]string
+}
+
+// Context of the message being handled, it should only be called inside HandleSend
+// and HandleAsk. Headers should not be modified, copy them to envelope of messages
+// sent by the actor to propagate them.
+func (m *LocalRef) Context() MessageContext {
+	if m.current == nil {
+		return MessageContext{}
+	}
+	c := MessageContext{
+		Sender: m.current.sender,
+	}
+	if env := m.current.envelope; env != nil {
+		c.CorrelationId, c.ReplyTo, c.Headers = env.CorrelationId, env.ReplyTo, env.Headers
+	}
+	return c
+}
+
+// Value of header, empty if it does not exist.
+func (c MessageContext) Header(key string) string {
+	return c.Headers[key]
+}
+
+func (c MessageContext) TraceId() string {
+	return c.Headers[HeaderTraceId]
+}
+
+func (c MessageContext) TenantId() string {
+	return c.Headers[HeaderTenantId]
+}
+
+// Deadline of message, ok is false if it has no valid deadline header.
+func (c MessageContext) Deadline() (deadline time.Time, ok bool) {
+	return parseDeadline(c.Headers)
+}
+
+func parseDeadline(headers map[string]string) (time.Time, bool) {
+	s, has := headers[HeaderDeadline]
+	if !has {
+		return time.Time{}, false
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return deadline, true
+}
+
+// Message which has passed its deadline is dropped as a dead letter, and ask of it
+// is answered with ErrDeadlineExceeded. Returns true if it has been dropped.
+func (m *LocalRef) dropExpired(msg *message) bool {
+	if msg.envelope == nil {
+		return false
+	}
+	deadline, ok := parseDeadline(msg.envelope.Headers)
+	if !ok || time.Now().Before(deadline) {
+		return false
+	}
+	m.local.sys.deadLetter(msg.sender, m.id, msg.msgContent, ErrDeadlineExceeded)
+	switch msg.msgType {
+	case msgTypeSend:
+		if msg.mailboxSeq > 0 {
+			m.mailbox.ack(msg.mailboxSeq)
+		}
+	case msgTypeAsk:
+		m.answer(message{
+			sender:     msg.sender,
+			msgSession: msg.msgSession,
+			msgType:    msgTypeAnswer,
+			msgError:   ErrDeadlineExceeded,
+		})
+	}
+	return true
+}
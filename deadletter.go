@@ -0,0 +1,39 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+// Topic of dead letters in actor.Events.
+const DeadLetterTopic = "actor.deadletter"
+
+//
+// Dead Letter
+//
+
+// A message which cannot be delivered, it is published to actor.Events with topic
+// DeadLetterTopic. Subscribe the topic, or subscribe type *DeadLetter, to audit
+// undeliverable messages. Dead letters are published locally, they are not
+// propagated to remote nodes.
+type DeadLetter struct {
+	// Sender of the message, it is nil if sender is unknown.
+	Sender Ref
+	// Target of the message, it only has a name if the actor of the name is not found.
+	Target Id
+	// Message which cannot be delivered, or an answer which cannot be returned.
+	Message interface{}
+	// Reason why the message cannot be delivered.
+	Reason error
+}
+
+func (m *system) deadLetter(sender Ref, target Id, msg interface{}, reason error) {
+	// a dead letter which cannot be delivered is dropped, to avoid recursion
+	if _, ok := msg.(*DeadLetter); ok {
+		return
+	}
+	m.events.publish(DeadLetterTopic, &DeadLetter{
+		Sender:  sender,
+		Target:  target,
+		Message: msg,
+		Reason:  reason,
+	}, false)
+}
@@ -0,0 +1,232 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Dispatcher decides which goroutine handles messages of an actor, see
+// PinnedDispatcher and NewPoolDispatcher.
+type Dispatcher interface {
+	// Start running an actor which has been started up.
+	start(r *LocalRef)
+	// Message has been put to mailbox of an actor.
+	schedule(r *LocalRef)
+	// Senders never block on full mailbox of an actor, such as an actor sharing
+	// workers with its senders.
+	nonBlocking() bool
+}
+
+// Spawn option, dispatcher of the actor, PinnedDispatcher is used by default.
+func WithDispatcher(d Dispatcher) SpawnOption {
+	return func(o *spawnOptions) {
+		if d != nil {
+			o.dispatcher = d
+		}
+	}
+}
+
+//
+// Pinned Dispatcher
+//
+
+// Each actor runs on its own dedicated goroutine, it is the default dispatcher,
+// and it suits actors doing blocking I/O.
+var PinnedDispatcher Dispatcher = pinnedDispatcher{}
+
+type pinnedDispatcher struct{}
+
+func (pinnedDispatcher) start(r *LocalRef) {
+	go r.spawn()
+}
+
+func (pinnedDispatcher) schedule(r *LocalRef) {
+}
+
+func (pinnedDispatcher) nonBlocking() bool {
+	return false
+}
+
+//
+// Pool Dispatcher
+//
+
+const (
+	dispatchIdle      int32 = 0
+	dispatchScheduled int32 = 1
+	dispatchExited    int32 = 2
+)
+
+// Actors share a fixed number of worker goroutines, an actor with messages in its
+// mailbox is scheduled to a worker, which handles at most throughput messages of
+// the actor per turn, then the actor is scheduled again if it has more messages.
+// It suits large numbers of lightweight actors. Send to an actor of pool dispatcher
+// never blocks, messages are queued without limit once its mailbox is full, so that
+// actors of the same pool are able to send to each other. PLEASE DO NOT block in
+// actors of pool dispatcher, such as blocking I/O, Ask and Send to actors of pinned
+// dispatcher, which blocks the worker, and might block the whole pool if all workers
+// are blocked.
+type PoolDispatcher struct {
+	throughput int
+	lock       sync.Mutex
+	cond       *sync.Cond
+	queue      []*LocalRef
+	closed     bool
+	workers    sync.WaitGroup
+}
+
+func NewPoolDispatcher(workers, throughput int) *PoolDispatcher {
+	if workers <= 0 {
+		workers = 1
+	}
+	if throughput <= 0 {
+		throughput = 1
+	}
+	m := &PoolDispatcher{
+		throughput: throughput,
+	}
+	m.cond = sync.NewCond(&m.lock)
+	m.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Stop workers of pool dispatcher, it waits until the scheduled actors have been
+// handled. Actors of the pool should be shut down before Close, messages put to
+// their mailboxes after Close are not handled. PLEASE DO NOT call it inside actors
+// of the pool.
+func (m *PoolDispatcher) Close() {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return
+	}
+	m.closed = true
+	m.lock.Unlock()
+	m.cond.Broadcast()
+	m.workers.Wait()
+}
+
+func (m *PoolDispatcher) start(r *LocalRef) {
+	m.schedule(r)
+}
+
+func (m *PoolDispatcher) nonBlocking() bool {
+	return true
+}
+
+func (m *PoolDispatcher) schedule(r *LocalRef) {
+	if !atomic.CompareAndSwapInt32(&r.dispatchState, dispatchIdle, dispatchScheduled) {
+		return
+	}
+	m.lock.Lock()
+	m.queue = append(m.queue, r)
+	m.lock.Unlock()
+	m.cond.Signal()
+}
+
+func (m *PoolDispatcher) work() {
+	defer m.workers.Done()
+	for {
+		m.lock.Lock()
+		for len(m.queue) == 0 && !m.closed {
+			m.cond.Wait()
+		}
+		if len(m.queue) == 0 {
+			m.lock.Unlock()
+			return
+		}
+		r := m.queue[0]
+		m.queue[0] = nil
+		m.queue = m.queue[1:]
+		m.lock.Unlock()
+
+		if !r.turn(m.throughput) {
+			atomic.StoreInt32(&r.dispatchState, dispatchExited)
+			continue
+		}
+		// unstashed messages can only be checked before the actor is idle, another
+		// worker might be handling the actor once it is idle
+		unstashed := len(r.unstashed) > 0
+		atomic.StoreInt32(&r.dispatchState, dispatchIdle)
+		// message might be put to mailbox before the actor is idle
+		if unstashed || len(r.recvCh) > 0 || r.hasOverflow() {
+			m.schedule(r)
+		}
+	}
+}
+
+// Handle at most throughput messages on a worker of pool dispatcher, returns false
+// if the actor has exited.
+func (m *LocalRef) turn(throughput int) (alive bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Actor %s recover from panic and exit\n", m.id.name)
+			m.local.crashActor(m)
+			alive = false
+		}
+	}()
+	if !m.dispatchReady {
+		m.dispatchReady = true
+		m.started()
+	}
+	for i := 0; i < throughput; i++ {
+		msg := m.pollMessage()
+		if msg == nil {
+			return true
+		}
+		if !m.process(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch next message without blocking, returns nil if mailbox is empty.
+func (m *LocalRef) pollMessage() *message {
+	if len(m.unstashed) > 0 {
+		return m.nextMessage()
+	}
+	select {
+	case msg := <-m.recvCh:
+		return msg
+	default:
+	}
+	m.overflowLock.Lock()
+	defer m.overflowLock.Unlock()
+	if len(m.overflow) == 0 {
+		return nil
+	}
+	msg := m.overflow[0]
+	m.overflow[0] = nil
+	m.overflow = m.overflow[1:]
+	return msg
+}
+
+// Put message to mailbox without blocking, message is queued to overflow if recvCh
+// is full. Messages of overflow are after messages of recvCh, since nothing is put
+// to recvCh until overflow is empty.
+func (m *LocalRef) offerMessage(msg *message) {
+	m.overflowLock.Lock()
+	defer m.overflowLock.Unlock()
+	if len(m.overflow) == 0 {
+		select {
+		case m.recvCh <- msg:
+			return
+		default:
+		}
+	}
+	m.overflow = append(m.overflow, msg)
+}
+
+func (m *LocalRef) hasOverflow() bool {
+	m.overflowLock.Lock()
+	defer m.overflowLock.Unlock()
+	return len(m.overflow) > 0
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+// Envelope wraps a message with its correlation id, reply-to reference and
+// headers. Send or Ask an *Envelope to any reference, the receiver handles the
+// wrapped Message, and reads the envelope with LocalRef.Envelope.
+// Over remote connections, ReplyTo should be a named actor, and Message should be
+// a ProtoBuf message or string.
+type Envelope struct {
+	CorrelationId string
+	ReplyTo       Ref
+	Headers       map[string]string
+	Message       interface{}
+}
+
+// Envelope of the message being handled, nil if the message is not enveloped.
+// It should only be called inside HandleSend and HandleAsk.
+func (m *LocalRef) Envelope() *Envelope {
+	if m.current == nil {
+		return nil
+	}
+	return m.current.envelope
+}
+
+// Send message back to the reply-to reference of the message being handled, or its
+// sender if it has no reply-to reference. Reply is enveloped with the correlation id
+// of the message being handled, if it has one.
+// It should only be called inside HandleSend and HandleAsk.
+func (m *LocalRef) Reply(msg interface{}) error {
+	if m.current == nil {
+		return ErrNoReplyTarget
+	}
+	target := m.current.sender
+	env := m.Envelope()
+	if env != nil && env.ReplyTo != nil {
+		target = env.ReplyTo
+	}
+	if target == nil {
+		return ErrNoReplyTarget
+	}
+	if env != nil && env.CorrelationId != "" {
+		msg = &Envelope{
+			CorrelationId: env.CorrelationId,
+			Message:       msg,
+		}
+	}
+	return target.Send(m, msg)
+}
+
+// Message of an envelope, or the message itself.
+func unwrapEnvelope(msg interface{}) (*Envelope, interface{}) {
+	if env, ok := msg.(*Envelope); ok && env != nil {
+		return env, env.Message
+	}
+	return nil, msg
+}
+
+//
+// Remote Envelope
+//
+
+func newConnEnvelope(env *Envelope) *ConnEnvelope {
+	if env == nil {
+		return nil
+	}
+	c := &ConnEnvelope{
+		CorrelationId: env.CorrelationId,
+		Headers:       env.Headers,
+	}
+	if env.ReplyTo != nil {
+		id := env.ReplyTo.Id()
+		c.ReplyToNode, c.ReplyToId, c.ReplyToName = id.node, id.id, id.name
+	}
+	return c
+}
+
+// Rebuild envelope of an incoming message from node via in, in is nil if it is
+// sent via outgoing connection.
+func (m *conn) envelope(in *inNode, nodeId uint32, c *ConnEnvelope, msg interface{}) interface{} {
+	if c == nil {
+		return msg
+	}
+	env := &Envelope{
+		CorrelationId: c.CorrelationId,
+		Headers:       c.Headers,
+		Message:       msg,
+	}
+	switch c.ReplyToNode {
+	case 0:
+		env.ReplyTo = m.remoteSender(in, nodeId, c.ReplyToId, c.ReplyToName)
+	case m.remote.nodeId:
+		if lr := m.remote.sys.ById(c.ReplyToId); lr != nil {
+			env.ReplyTo = lr
+		}
+	default:
+		env.ReplyTo = m.remoteSender(nil, c.ReplyToNode, c.ReplyToId, c.ReplyToName)
+	}
+	return env
+}
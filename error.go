@@ -15,6 +15,23 @@ var (
 	ErrAskType               = errors.New("actor ask type error")
 	ErrAnswerType            = errors.New("actor answer type error")
 	ErrMessageValue          = errors.New("message value error")
+	ErrMessageType           = errors.New("message type error")
+	ErrMessageUnhandled      = errors.New("message unhandled")
+	ErrNoReplyTarget         = errors.New("message has no reply target")
+	ErrDeadlineExceeded      = errors.New("message deadline exceeded")
+	ErrContentType           = errors.New("content type error")
+	ErrNotPersistentActor    = errors.New("not persistent actor")
+	ErrDurableMailboxName    = errors.New("durable mailbox requires actor name")
+	ErrRouterNoRoutee        = errors.New("router has no running routee")
+	ErrRouterBroadcastAsk    = errors.New("router broadcast cannot ask")
+	ErrRouterHashKey         = errors.New("router message has no hash key")
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrEventQueueFull        = errors.New("event subscriber queue is full")
+	ErrPassivationName       = errors.New("passivation requires actor name")
+	ErrStashFull             = errors.New("actor stash is full")
+	ErrStashNoMessage        = errors.New("actor has no message to stash")
+	ErrFSMNotStarted         = errors.New("fsm not started")
+	ErrFSMEventUnhandled     = errors.New("fsm event unhandled")
 	ErrNodeId                = errors.New("actor.Remote error node id")
 	ErrRemoteRefSendType     = errors.New("actor.Remote remote ref send type error")
 	ErrRemoteRefAskType      = errors.New("actor.Remote remote ref ask type error")
@@ -25,6 +42,11 @@ var (
 	ErrRemoteResponse        = errors.New("actor.Remote remote request error")
 	ErrRemoteTimeout         = errors.New("actor.Remote remote timeout error")
 	ErrRemoteActorNotFound   = errors.New("actor.Remote remote actor not found")
+	ErrRemoteNodeDown        = errors.New("actor.Remote remote node down")
+	ErrRemotePending         = errors.New("actor.Remote remote message pending for retry")
+	ErrReliableNotEnabled    = errors.New("actor.Remote reliable delivery not enabled")
+	ErrReliableEnabled       = errors.New("actor.Remote reliable delivery enabled")
+	ErrReliableExpired       = errors.New("actor.Remote reliable message expired")
 	ErrPacketInvalid         = errors.New("conn packet invalid")
 	ErrConnError             = errors.New("conn error")
 	ErrAuthFailed            = errors.New("conn auth failed")
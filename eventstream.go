@@ -0,0 +1,364 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Fast way to get the pointer of eventStream.
+var Events *eventStream
+
+//
+// Event Stream
+//
+
+// Event stream is a system-level publish/subscribe event bus. Topics are separated
+// by dots, such as "order.created". A subscribed topic might contain wildcards,
+// "*" matches exactly one segment, and "#" matches zero or more segments, such as
+// "order.*" and "order.#". Subscribers receive published messages with their
+// HandleSend method, and local subscribers are unsubscribed once they have halt.
+// Messages are queued to subscribers, so that publishers are never blocked by
+// subscribers whose mailboxes are full.
+type eventStream struct {
+	sys          *system
+	lock         sync.RWMutex
+	topics       map[string]map[idKey]Ref
+	types        map[reflect.Type]map[idKey]Ref
+	propagations []eventPropagation
+	// messages waiting to be sent to subscribers
+	queues     map[idKey][]interface{}
+	queuesLock sync.Mutex
+}
+
+// Messages queued to a subscriber are limited, more messages are dead letters.
+const eventQueueSize = 1024
+
+type eventPropagation struct {
+	conn    *RemoteConn
+	pattern string
+}
+
+func (m *eventStream) init(sys *system) {
+	m.sys = sys
+	m.topics = map[string]map[idKey]Ref{}
+	m.types = map[reflect.Type]map[idKey]Ref{}
+	m.queues = map[idKey][]interface{}{}
+}
+
+// Subscribe messages published to the topics which match the pattern.
+func (m *eventStream) Subscribe(ref Ref, pattern string) error {
+	if ref == nil || !validTopicPattern(pattern) {
+		return ErrArgument
+	}
+	m.lock.Lock()
+	subs, has := m.topics[pattern]
+	if !has {
+		subs = map[idKey]Ref{}
+		m.topics[pattern] = subs
+	}
+	subs[ref.Id().key()] = ref
+	m.lock.Unlock()
+	return m.watch(ref)
+}
+
+// Subscribe messages of the same type as the prototype, no matter which topic they
+// are published to.
+func (m *eventStream) SubscribeType(ref Ref, prototype interface{}) error {
+	if ref == nil || prototype == nil {
+		return ErrArgument
+	}
+	t := reflect.TypeOf(prototype)
+	m.lock.Lock()
+	subs, has := m.types[t]
+	if !has {
+		subs = map[idKey]Ref{}
+		m.types[t] = subs
+	}
+	subs[ref.Id().key()] = ref
+	m.lock.Unlock()
+	return m.watch(ref)
+}
+
+// Unsubscribe the local subscriber once it has halt, and drop its queued messages.
+func (m *eventStream) watch(ref Ref) error {
+	lr, ok := ref.(*LocalRef)
+	if !ok {
+		return nil
+	}
+	if !lr.addHaltHook(m, func(ref *LocalRef) {
+		m.UnsubscribeAll(ref)
+		m.dropQueue(ref)
+	}) {
+		m.UnsubscribeAll(ref)
+		return ErrActorNotRunning
+	}
+	return nil
+}
+
+func (m *eventStream) Unsubscribe(ref Ref, pattern string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if subs, has := m.topics[pattern]; has {
+		delete(subs, ref.Id().key())
+		if len(subs) == 0 {
+			delete(m.topics, pattern)
+		}
+	}
+}
+
+func (m *eventStream) UnsubscribeType(ref Ref, prototype interface{}) {
+	t := reflect.TypeOf(prototype)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if subs, has := m.types[t]; has {
+		delete(subs, ref.Id().key())
+		if len(subs) == 0 {
+			delete(m.types, t)
+		}
+	}
+}
+
+// Unsubscribe all topics and types of a subscriber.
+func (m *eventStream) UnsubscribeAll(ref Ref) {
+	id := ref.Id().key()
+	m.lock.Lock()
+	for pattern, subs := range m.topics {
+		delete(subs, id)
+		if len(subs) == 0 {
+			delete(m.topics, pattern)
+		}
+	}
+	for t, subs := range m.types {
+		delete(subs, id)
+		if len(subs) == 0 {
+			delete(m.types, t)
+		}
+	}
+	m.lock.Unlock()
+	if lr, ok := ref.(*LocalRef); ok {
+		lr.delHaltHook(m)
+	}
+}
+
+// Publish message to a topic, topic should not contain wildcards. Each subscriber
+// receives the message once, even if it has subscribed more than one matched
+// pattern. Returns the number of subscribers which the message has been queued to.
+func (m *eventStream) Publish(topic string, msg interface{}) int {
+	return m.publish(topic, msg, true)
+}
+
+func (m *eventStream) publish(topic string, msg interface{}, propagate bool) int {
+	if strings.ContainsAny(topic, "*#") {
+		return 0
+	}
+	subs := map[idKey]Ref{}
+	var propagations []eventPropagation
+	m.lock.RLock()
+	for pattern, refs := range m.topics {
+		if matchTopic(pattern, topic) {
+			for id, ref := range refs {
+				subs[id] = ref
+			}
+		}
+	}
+	if msg != nil {
+		for id, ref := range m.types[reflect.TypeOf(msg)] {
+			subs[id] = ref
+		}
+	}
+	if propagate {
+		for _, p := range m.propagations {
+			if matchTopic(p.pattern, topic) {
+				propagations = append(propagations, p)
+			}
+		}
+	}
+	m.lock.RUnlock()
+
+	count := 0
+	for _, ref := range subs {
+		if ref.Status() == Halt {
+			m.UnsubscribeAll(ref)
+			continue
+		}
+		if m.enqueue(ref, msg) {
+			count++
+		}
+	}
+	for _, p := range propagations {
+		m.propagateTo(p, topic, msg)
+	}
+	return count
+}
+
+// Queue message to subscriber, messages of a subscriber are sent one by one in
+// order, by a goroutine which exits once the queue is empty.
+func (m *eventStream) enqueue(ref Ref, msg interface{}) bool {
+	id := ref.Id().key()
+	m.queuesLock.Lock()
+	if queue, has := m.queues[id]; has {
+		if len(queue) >= eventQueueSize {
+			m.queuesLock.Unlock()
+			m.sys.deadLetter(nil, ref.Id(), msg, ErrEventQueueFull)
+			return false
+		}
+		m.queues[id] = append(queue, msg)
+		m.queuesLock.Unlock()
+		return true
+	}
+	m.queues[id] = []interface{}{}
+	m.queuesLock.Unlock()
+	go func() {
+		for {
+			if err := ref.Send(nil, msg); err != nil && ref.Status() == Halt {
+				m.UnsubscribeAll(ref)
+			}
+			m.queuesLock.Lock()
+			queue := m.queues[id]
+			if len(queue) == 0 {
+				delete(m.queues, id)
+				m.queuesLock.Unlock()
+				return
+			}
+			msg, m.queues[id] = queue[0], queue[1:]
+			m.queuesLock.Unlock()
+		}
+	}()
+	return true
+}
+
+// Messages queued to a halted subscriber are dead letters, the goroutine sending
+// to the subscriber exits after its current message.
+func (m *eventStream) dropQueue(ref Ref) {
+	id := ref.Id().key()
+	m.queuesLock.Lock()
+	queue, has := m.queues[id]
+	if has {
+		m.queues[id] = []interface{}{}
+	}
+	m.queuesLock.Unlock()
+	for _, msg := range queue {
+		m.sys.deadLetter(nil, ref.Id(), msg, ErrActorNotRunning)
+	}
+}
+
+//
+// Distributed topics
+//
+
+// Propagate messages published locally to the topics which match the pattern, to
+// the event stream of a remote node. Messages should be ProtoBuf messages, or basic
+// types such as bool, []byte, string, int and float.
+// Propagation stops once the connection is down.
+func (m *eventStream) Propagate(conn *RemoteConn, pattern string) error {
+	if conn == nil || conn.node == nil || !validTopicPattern(pattern) {
+		return ErrArgument
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, p := range m.propagations {
+		if p.conn.node == conn.node && p.pattern == pattern {
+			return nil
+		}
+	}
+	m.propagations = append(m.propagations, eventPropagation{conn: conn, pattern: pattern})
+	return nil
+}
+
+func (m *eventStream) StopPropagate(conn *RemoteConn, pattern string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i, p := range m.propagations {
+		if p.conn.node == conn.node && p.pattern == pattern {
+			m.propagations = append(m.propagations[:i:i], m.propagations[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *eventStream) propagateTo(p eventPropagation, topic string, msg interface{}) {
+	data, err := encodeContent(msg)
+	if err != nil {
+		log.Println("actor.Events propagate message type error,", topic, err)
+		return
+	}
+	err = p.conn.node.post(&ConnMessage{
+		Type: ControlType_CPublish,
+		Content: &ConnMessage_Publish{
+			Publish: &Publish{
+				Topic: topic,
+				Data:  data,
+			},
+		},
+	})
+	if err != nil {
+		log.Println("actor.Events propagate error,", topic, err)
+		if !p.conn.node.isReady() {
+			m.StopPropagate(p.conn, p.pattern)
+		}
+	}
+}
+
+// Handle message published by remote node, it is not propagated again.
+func (m *eventStream) handleRemotePublish(publish *Publish) {
+	if publish == nil || publish.Data == nil {
+		log.Println("actor.Events remote publish empty message")
+		return
+	}
+	msg, err := decodeContent(publish.Data)
+	if err != nil {
+		log.Println("actor.Events remote publish message error,", publish.Topic, err)
+		return
+	}
+	m.publish(publish.Topic, msg, false)
+}
+
+//
+// Topic matching
+//
+
+func validTopicPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	for _, segment := range strings.Split(pattern, ".") {
+		if segment == "" || (strings.ContainsAny(segment, "*#") && len(segment) > 1) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchTopic(pattern, topic string) bool {
+	return matchSegments(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+func matchSegments(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			// zero or more segments
+			for i := 0; i <= len(topic); i++ {
+				if matchSegments(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || pattern[0] != topic[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
+}
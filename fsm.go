@@ -0,0 +1,173 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"reflect"
+	"time"
+)
+
+// Topic of FSM transitions, transitions of all FSM actors are published to this
+// topic of local event stream as *FSMTransition.
+const FSMTransitionTopic = "actor.fsm"
+
+type FSMState string
+
+// Handler of an event in a state, returns the next state. Returning the current
+// state stays in the state, and entry and exit hooks are not called.
+type FSMHandler func(sender Ref, event interface{}) FSMState
+
+// Transition of FSM, for tracing.
+type FSMTransition struct {
+	Ref   *LocalRef
+	From  FSMState
+	To    FSMState
+	Event interface{}
+	At    time.Time
+}
+
+// Event sent to FSM actor when it has stayed in a state longer than the timeout of
+// the state, see FSM.SetStateTimeout.
+type FSMStateTimeout struct {
+	State FSMState
+	entry uint64
+}
+
+//
+// FSM
+//
+
+// Finite state machine, embed it to an actor and it handles messages with its
+// transition table. Events are matched by their types, so events should be
+// distinct types, such as struct types.
+// Define transition table, hooks and timeouts in StartUp, then call StartFSM.
+// FSM provides HandleSend method, unhandled event is a dead letter.
+type FSM struct {
+	self        *LocalRef
+	state       FSMState
+	table       map[FSMState]map[reflect.Type]FSMHandler
+	entryHooks  map[FSMState]func(from FSMState)
+	exitHooks   map[FSMState]func(to FSMState)
+	timeouts    map[FSMState]time.Duration
+	timeout     Cancelable
+	entryCount  uint64
+	transitions []func(t *FSMTransition)
+}
+
+func (m *FSM) lazyInit() {
+	if m.table == nil {
+		m.table = map[FSMState]map[reflect.Type]FSMHandler{}
+		m.entryHooks = map[FSMState]func(from FSMState){}
+		m.exitHooks = map[FSMState]func(to FSMState){}
+		m.timeouts = map[FSMState]time.Duration{}
+	}
+}
+
+// Handle events of the type of event prototype in the state with handler.
+func (m *FSM) On(state FSMState, event interface{}, handler FSMHandler) {
+	m.lazyInit()
+	handlers, has := m.table[state]
+	if !has {
+		handlers = map[reflect.Type]FSMHandler{}
+		m.table[state] = handlers
+	}
+	handlers[reflect.TypeOf(event)] = handler
+}
+
+// Hook called when FSM has entered the state.
+func (m *FSM) OnEntry(state FSMState, fn func(from FSMState)) {
+	m.lazyInit()
+	m.entryHooks[state] = fn
+}
+
+// Hook called when FSM is going to exit the state.
+func (m *FSM) OnExit(state FSMState, fn func(to FSMState)) {
+	m.lazyInit()
+	m.exitHooks[state] = fn
+}
+
+// Hook called after each transition.
+func (m *FSM) OnTransition(fn func(t *FSMTransition)) {
+	m.transitions = append(m.transitions, fn)
+}
+
+// FSMStateTimeout event is sent to the actor once it has stayed in the state for
+// the duration, handle it with On(state, FSMStateTimeout{}, handler).
+func (m *FSM) SetStateTimeout(state FSMState, d time.Duration) {
+	m.lazyInit()
+	m.timeouts[state] = d
+}
+
+// Start FSM in the initial state, entry hook of initial state is called.
+func (m *FSM) StartFSM(self *LocalRef, initial FSMState) {
+	m.lazyInit()
+	m.self = self
+	m.enter("", initial, nil)
+}
+
+// Current state.
+func (m *FSM) State() FSMState {
+	return m.state
+}
+
+// Handle event in current state, returns ErrFSMEventUnhandled if there is no
+// handler of the event in current state.
+func (m *FSM) Fire(sender Ref, event interface{}) error {
+	if m.self == nil {
+		return ErrFSMNotStarted
+	}
+	if t, ok := event.(FSMStateTimeout); ok && (t.State != m.state || t.entry != m.entryCount) {
+		// timeout of previous state
+		return nil
+	}
+	handler, has := m.table[m.state][reflect.TypeOf(event)]
+	if !has {
+		return ErrFSMEventUnhandled
+	}
+	from := m.state
+	to := handler(sender, event)
+	if to == from {
+		return nil
+	}
+	if fn, has := m.exitHooks[from]; has {
+		fn(to)
+	}
+	m.enter(from, to, event)
+	return nil
+}
+
+func (m *FSM) HandleSend(sender Ref, message interface{}) {
+	if err := m.Fire(sender, message); err != nil && m.self != nil {
+		m.self.local.sys.deadLetter(sender, m.self.id, message, err)
+	}
+}
+
+func (m *FSM) enter(from, to FSMState, event interface{}) {
+	if m.timeout != nil {
+		m.timeout.Cancel()
+		m.timeout = nil
+	}
+	m.state = to
+	m.entryCount++
+	if d, has := m.timeouts[to]; has && d > 0 {
+		m.timeout = m.self.SendAfter(d, m.self, FSMStateTimeout{
+			State: to,
+			entry: m.entryCount,
+		})
+	}
+	if fn, has := m.entryHooks[to]; has {
+		fn(from)
+	}
+	t := &FSMTransition{
+		Ref:   m.self,
+		From:  from,
+		To:    to,
+		Event: event,
+		At:    time.Now(),
+	}
+	for _, fn := range m.transitions {
+		fn(t)
+	}
+	m.self.local.sys.events.publish(FSMTransitionTopic, t, false)
+}
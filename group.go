@@ -0,0 +1,250 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"reflect"
+	"sync"
+)
+
+//
+// Group
+//
+
+// Get a named group of the default system, it is created if not exists.
+func Group(name string) *GroupRef {
+	return defaultSys.Group(name)
+}
+
+// Delete a named group of the default system.
+func DelGroup(name string) {
+	defaultSys.DelGroup(name)
+}
+
+// Batch send a message to a basket of references, no matter LocalRef or RemoteRef.
+// Returns errors of the references which have failed, keyed by their ids.
+func BatchSend(sender Ref, targets []Ref, msg interface{}) map[Id]error {
+	errs := map[Id]error{}
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target Ref) {
+			defer wg.Done()
+			if err := target.Send(sender, msg); err != nil {
+				lock.Lock()
+				errs[target.Id()] = err
+				lock.Unlock()
+			}
+		}(target)
+	}
+	wg.Wait()
+	return errs
+}
+
+type groupsManager struct {
+	sys    *system
+	groups map[string]*GroupRef
+	lock   sync.Mutex
+}
+
+func (m *groupsManager) init(sys *system) {
+	m.sys = sys
+	m.groups = map[string]*GroupRef{}
+}
+
+func (m *system) Group(name string) *GroupRef {
+	m.groups.lock.Lock()
+	defer m.groups.lock.Unlock()
+	g, has := m.groups.groups[name]
+	if !has {
+		g = m.NewGroup(name)
+		m.groups.groups[name] = g
+	}
+	return g
+}
+
+func (m *system) DelGroup(name string) {
+	m.groups.lock.Lock()
+	g := m.groups.groups[name]
+	delete(m.groups.groups, name)
+	m.groups.lock.Unlock()
+	if g != nil {
+		g.Clear()
+	}
+}
+
+// Create a group which is not registered to system.
+func (m *system) NewGroup(name string) *GroupRef {
+	return &GroupRef{
+		sys:     m,
+		name:    name,
+		members: map[idKey]Ref{},
+		names:   map[string]bool{},
+	}
+}
+
+// Group of references, no matter LocalRef or RemoteRef. A message sent to group is
+// sent to all its members. Members which have halt are removed automatically.
+type GroupRef struct {
+	sys     *system
+	name    string
+	lock    sync.RWMutex
+	members map[idKey]Ref
+	names   map[string]bool
+}
+
+func (m *GroupRef) Name() string {
+	return m.name
+}
+
+// Add a reference to group.
+func (m *GroupRef) AddRef(ref Ref) error {
+	if ref == nil || ref.Status() != Running {
+		return ErrActorNotRunning
+	}
+	m.lock.Lock()
+	m.members[ref.Id().key()] = ref
+	m.lock.Unlock()
+	if lr, ok := ref.(*LocalRef); ok {
+		if !lr.addHaltHook(m, m.onHalt) {
+			m.DelRef(ref)
+			return ErrActorNotRunning
+		}
+	}
+	return nil
+}
+
+// Add a local actor name to group, it is resolved each time a message is sent to
+// group, so that the member is kept when the actor of the name has been respawned.
+func (m *GroupRef) AddName(name string) error {
+	if name == "" {
+		return ErrArgument
+	}
+	m.lock.Lock()
+	m.names[name] = true
+	m.lock.Unlock()
+	return nil
+}
+
+// Delete a reference from group.
+func (m *GroupRef) DelRef(ref Ref) {
+	m.lock.Lock()
+	delete(m.members, ref.Id().key())
+	m.lock.Unlock()
+	if lr, ok := ref.(*LocalRef); ok {
+		lr.delHaltHook(m)
+	}
+}
+
+// Delete a local actor name from group.
+func (m *GroupRef) DelName(name string) {
+	m.lock.Lock()
+	delete(m.names, name)
+	m.lock.Unlock()
+}
+
+// Delete all members of group.
+func (m *GroupRef) Clear() {
+	for _, ref := range m.refs() {
+		m.DelRef(ref)
+	}
+	m.lock.Lock()
+	m.names = map[string]bool{}
+	m.lock.Unlock()
+}
+
+func (m *GroupRef) onHalt(ref *LocalRef) {
+	m.lock.Lock()
+	delete(m.members, ref.Id().key())
+	m.lock.Unlock()
+}
+
+func (m *GroupRef) refs() []Ref {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	refs := make([]Ref, 0, len(m.members))
+	for _, ref := range m.members {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// Members of group, with names resolved. Members which have halt are removed,
+// and names which cannot be resolved are returned as errors.
+func (m *GroupRef) Members() ([]Ref, map[Id]error) {
+	var members []Ref
+	// an actor might be added by both reference and name
+	added := map[idKey]bool{}
+	for _, ref := range m.refs() {
+		if ref.Status() == Halt {
+			m.DelRef(ref)
+			continue
+		}
+		added[ref.Id().key()] = true
+		members = append(members, ref)
+	}
+	errs := map[Id]error{}
+	m.lock.RLock()
+	names := make([]string, 0, len(m.names))
+	for name := range m.names {
+		names = append(names, name)
+	}
+	m.lock.RUnlock()
+	for _, name := range names {
+		if lr := m.sys.ByName(name); lr != nil {
+			if k := lr.id.key(); !added[k] {
+				added[k] = true
+				members = append(members, lr)
+			}
+		} else {
+			errs[Id{name: name}] = ErrActorNotRunning
+		}
+	}
+	return members, errs
+}
+
+// Send message to all members, returns errors of the members which have failed,
+// keyed by their ids.
+func (m *GroupRef) Send(sender Ref, msg interface{}) map[Id]error {
+	members, errs := m.Members()
+	for id, err := range BatchSend(sender, members, msg) {
+		errs[id] = err
+	}
+	return errs
+}
+
+// Ask all members, answer parameter should be a pointer, it is used as a prototype
+// of answers, and it is not modified. Returns answers of the members which have
+// succeeded, and errors of the members which have failed, keyed by their ids.
+func (m *GroupRef) Ask(sender Ref, ask interface{}, answer interface{}) (map[Id]interface{}, map[Id]error) {
+	members, errs := m.Members()
+	answers := map[Id]interface{}{}
+	answerType := reflect.TypeOf(answer)
+	if answerType == nil || answerType.Kind() != reflect.Ptr {
+		for _, ref := range members {
+			errs[ref.Id()] = ErrAnswerType
+		}
+		return answers, errs
+	}
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, ref := range members {
+		wg.Add(1)
+		go func(ref Ref) {
+			defer wg.Done()
+			a := reflect.New(answerType.Elem())
+			err := ref.Ask(sender, ask, a.Interface())
+			lock.Lock()
+			if err != nil {
+				errs[ref.Id()] = err
+			} else {
+				answers[ref.Id()] = a.Elem().Interface()
+			}
+			lock.Unlock()
+		}(ref)
+	}
+	wg.Wait()
+	return answers, errs
+}
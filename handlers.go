@@ -0,0 +1,120 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import "reflect"
+
+//
+// Message Handlers
+//
+
+// Handlers routes messages to handlers registered by message type, instead of type
+// switches in HandleSend and HandleAsk. Embed it to an actor, call InitHandlers in
+// StartUp, and register handlers with On and OnAsk.
+// Handler of a concrete type is matched first, then handlers of interface types in
+// registration order. Unhandled messages go to the fallback, or dead letters if
+// there is no fallback, and unhandled asks are answered with ErrMessageUnhandled.
+type Handlers struct {
+	self        *LocalRef
+	sends       map[reflect.Type]func(sender Ref, msg interface{})
+	asks        map[reflect.Type]func(sender Ref, ask interface{}) (interface{}, error)
+	sendIfaces  []reflect.Type
+	askIfaces   []reflect.Type
+	fallback    func(sender Ref, msg interface{})
+	askFallback func(sender Ref, ask interface{}) (interface{}, error)
+}
+
+func (m *Handlers) InitHandlers(self *LocalRef) {
+	m.self = self
+	m.lazyInit()
+}
+
+func (m *Handlers) lazyInit() {
+	if m.sends == nil {
+		m.sends = map[reflect.Type]func(sender Ref, msg interface{}){}
+		m.asks = map[reflect.Type]func(sender Ref, ask interface{}) (interface{}, error){}
+	}
+}
+
+// Handle sent messages which are not handled by registered handlers.
+func (m *Handlers) SetFallback(fn func(sender Ref, msg interface{})) {
+	m.fallback = fn
+}
+
+// Handle asks which are not handled by registered handlers.
+func (m *Handlers) SetAskFallback(fn func(sender Ref, ask interface{}) (interface{}, error)) {
+	m.askFallback = fn
+}
+
+// Register handler of sent messages of type T.
+func On[T any](m *Handlers, fn func(sender Ref, msg T)) {
+	m.lazyInit()
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if _, has := m.sends[t]; !has && t.Kind() == reflect.Interface {
+		m.sendIfaces = append(m.sendIfaces, t)
+	}
+	m.sends[t] = func(sender Ref, msg interface{}) {
+		fn(sender, msg.(T))
+	}
+}
+
+// Register handler of asks of type T, which answers R.
+func OnAsk[T, R any](m *Handlers, fn func(sender Ref, ask T) (R, error)) {
+	m.lazyInit()
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if _, has := m.asks[t]; !has && t.Kind() == reflect.Interface {
+		m.askIfaces = append(m.askIfaces, t)
+	}
+	m.asks[t] = func(sender Ref, ask interface{}) (interface{}, error) {
+		return fn(sender, ask.(T))
+	}
+}
+
+func (m *Handlers) HandleSend(sender Ref, message interface{}) {
+	if message != nil {
+		t := reflect.TypeOf(message)
+		if fn, has := m.sends[t]; has {
+			fn(sender, message)
+			return
+		}
+		for _, it := range m.sendIfaces {
+			if t.Implements(it) {
+				m.sends[it](sender, message)
+				return
+			}
+		}
+	}
+	if m.fallback != nil {
+		m.fallback(sender, message)
+		return
+	}
+	m.deadLetter(sender, message)
+}
+
+func (m *Handlers) HandleAsk(sender Ref, ask interface{}) (answer interface{}, err error) {
+	if ask != nil {
+		t := reflect.TypeOf(ask)
+		if fn, has := m.asks[t]; has {
+			return fn(sender, ask)
+		}
+		for _, it := range m.askIfaces {
+			if t.Implements(it) {
+				return m.asks[it](sender, ask)
+			}
+		}
+	}
+	if m.askFallback != nil {
+		return m.askFallback(sender, ask)
+	}
+	m.deadLetter(sender, ask)
+	return nil, ErrMessageUnhandled
+}
+
+func (m *Handlers) deadLetter(sender Ref, msg interface{}) {
+	if m.self == nil {
+		defaultSys.deadLetter(sender, Id{}, msg, ErrMessageUnhandled)
+		return
+	}
+	m.self.local.sys.deadLetter(sender, m.self.id, msg, ErrMessageUnhandled)
+}
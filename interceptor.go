@@ -0,0 +1,186 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import "sync"
+
+// Message being dispatched to an actor, interceptors may modify Message before
+// passing it to the next handler. Message has been unwrapped from its Envelope.
+type InboundContext struct {
+	Self     *LocalRef
+	Sender   Ref
+	Message  interface{}
+	Envelope *Envelope
+	IsAsk    bool
+}
+
+// Dispatch message to the actor, answer is nil if it is not an ask.
+type InboundHandler func(ctx *InboundContext) (answer interface{}, err error)
+
+// Interceptor wraps message dispatching of actors, it should call next to dispatch
+// message, or return without calling next to reject it. Error of a rejected send
+// makes the message a dead letter, and error of an ask is answered to the asker.
+type InboundInterceptor func(ctx *InboundContext, next InboundHandler) (answer interface{}, err error)
+
+// Message being sent to a reference, interceptors may modify Message before
+// passing it to the next handler. Answer is the answer pointer of an ask.
+type OutboundContext struct {
+	Sender  Ref
+	Target  Ref
+	Message interface{}
+	IsAsk   bool
+	Answer  interface{}
+}
+
+// Send or ask the target.
+type OutboundHandler func(ctx *OutboundContext) error
+
+// Interceptor wraps Send and Ask of references, it should call next to send the
+// message, or return an error without calling next to reject it.
+type OutboundInterceptor func(ctx *OutboundContext, next OutboundHandler) error
+
+// Add inbound interceptor to all actors of the default system, returns a function
+// which removes the interceptor.
+func AddInboundInterceptor(i InboundInterceptor) (remove func()) {
+	return defaultSys.AddInboundInterceptor(i)
+}
+
+// Add outbound interceptor to all references of the default system, returns a
+// function which removes the interceptor.
+func AddOutboundInterceptor(i OutboundInterceptor) (remove func()) {
+	return defaultSys.AddOutboundInterceptor(i)
+}
+
+// Spawn option, inbound interceptors of the actor, which run inside system-wide
+// inbound interceptors.
+func WithInboundInterceptors(i ...InboundInterceptor) SpawnOption {
+	return func(o *spawnOptions) {
+		o.inbound = append(o.inbound, i...)
+	}
+}
+
+// Spawn option, outbound interceptors of messages sent by the actor, which run
+// inside system-wide outbound interceptors. Messages are sent by the actor if the
+// sender is the actor.
+func WithOutboundInterceptors(i ...OutboundInterceptor) SpawnOption {
+	return func(o *spawnOptions) {
+		o.outbound = append(o.outbound, i...)
+	}
+}
+
+//
+// Interceptors
+//
+
+// Chains are copied on write, ids are parallel to the chains, functions cannot
+// be compared when removing.
+type interceptors struct {
+	lock        sync.RWMutex
+	lastId      uint64
+	inbound     []InboundInterceptor
+	inboundIds  []uint64
+	outbound    []OutboundInterceptor
+	outboundIds []uint64
+}
+
+func (m *interceptors) addInbound(i InboundInterceptor) func() {
+	m.lock.Lock()
+	m.lastId++
+	id := m.lastId
+	m.inbound = append(m.inbound[:len(m.inbound):len(m.inbound)], i)
+	m.inboundIds = append(m.inboundIds, id)
+	m.lock.Unlock()
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		for n, v := range m.inboundIds {
+			if v == id {
+				m.inbound = append(m.inbound[:n:n], m.inbound[n+1:]...)
+				m.inboundIds = append(m.inboundIds[:n:n], m.inboundIds[n+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (m *interceptors) addOutbound(i OutboundInterceptor) func() {
+	m.lock.Lock()
+	m.lastId++
+	id := m.lastId
+	m.outbound = append(m.outbound[:len(m.outbound):len(m.outbound)], i)
+	m.outboundIds = append(m.outboundIds, id)
+	m.lock.Unlock()
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		for n, v := range m.outboundIds {
+			if v == id {
+				m.outbound = append(m.outbound[:n:n], m.outbound[n+1:]...)
+				m.outboundIds = append(m.outboundIds[:n:n], m.outboundIds[n+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Dispatch message to actor through system-wide and actor interceptors.
+func (m *interceptors) dispatch(self *LocalRef, msg *message, handler InboundHandler) (interface{}, error) {
+	m.lock.RLock()
+	chain := m.inbound
+	m.lock.RUnlock()
+	ctx := &InboundContext{
+		Self:   self,
+		Sender: msg.sender,
+		IsAsk:  msg.msgType == msgTypeAsk,
+	}
+	ctx.Envelope, ctx.Message = msg.envelope, msg.msgContent
+	if len(chain) == 0 && len(self.inbound) == 0 {
+		return handler(ctx)
+	}
+	return inboundChain(chain, inboundChain(self.inbound, handler))(ctx)
+}
+
+func inboundChain(chain []InboundInterceptor, handler InboundHandler) InboundHandler {
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], handler
+		handler = func(ctx *InboundContext) (interface{}, error) {
+			return interceptor(ctx, next)
+		}
+	}
+	return handler
+}
+
+// Send or ask target through system-wide and sender interceptors.
+func (m *interceptors) send(ctx *OutboundContext, handler OutboundHandler) error {
+	m.lock.RLock()
+	chain := m.outbound
+	m.lock.RUnlock()
+	var local []OutboundInterceptor
+	if lr, ok := ctx.Sender.(*LocalRef); ok && lr != nil {
+		local = lr.outbound
+	}
+	if len(chain) == 0 && len(local) == 0 {
+		return handler(ctx)
+	}
+	return outboundChain(chain, outboundChain(local, handler))(ctx)
+}
+
+func outboundChain(chain []OutboundInterceptor, handler OutboundHandler) OutboundHandler {
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], handler
+		handler = func(ctx *OutboundContext) error {
+			return interceptor(ctx, next)
+		}
+	}
+	return handler
+}
+
+func (m *LocalRef) dispatchSend(ctx *InboundContext) (interface{}, error) {
+	m.handleSend(ctx.Sender, ctx.Message)
+	return nil, nil
+}
+
+func (m *LocalRef) dispatchAsk(ctx *InboundContext) (interface{}, error) {
+	return m.handleAsk(ctx.Sender, ctx.Message)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -68,6 +69,9 @@ func (m *localsManager) newActorRef(a Actor) *LocalRef {
 }
 
 func (m *localsManager) getActorRef(id uint32) *LocalRef {
+	m.idCountLock.Lock()
+	defer m.idCountLock.Unlock()
+
 	r, has := m.actors[id]
 	if !has {
 		return nil
@@ -83,9 +87,41 @@ func (m *localsManager) delActorRef(id uint32) {
 	delete(m.actors, id)
 }
 
+func (m *localsManager) count() int {
+	m.idCountLock.Lock()
+	defer m.idCountLock.Unlock()
+
+	return len(m.actors)
+}
+
+// spawn options
+
+// Option of spawning an actor, such as WithDurableMailbox.
+type SpawnOption func(o *spawnOptions)
+
+type spawnOptions struct {
+	durableMailboxDir string
+	passivateIdle     time.Duration
+	stashCapacity     int
+	inbound           []InboundInterceptor
+	outbound          []OutboundInterceptor
+	panicPolicy       PanicPolicy
+	dispatcher        Dispatcher
+}
+
 // actors life cycles
 
-func (m *localsManager) spawnActor(fn func() Actor, name string, arg interface{}) (*LocalRef, error) {
+func (m *localsManager) spawnActor(fn func() Actor, name string, arg interface{}, opts []SpawnOption) (*LocalRef, error) {
+	o := spawnOptions{
+		stashCapacity: DefaultStashCapacity,
+		dispatcher:    PinnedDispatcher,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.passivateIdle > 0 && name == "" {
+		return nil, ErrPassivationName
+	}
 	// #1 create actor with constructor function
 	a := fn()
 	// #2 new actor reference to hold created actor
@@ -95,35 +131,88 @@ func (m *localsManager) spawnActor(fn func() Actor, name string, arg interface{}
 		return nil, err
 	}
 	r.setStatus(StartingUp)
+	r.stashCapacity = o.stashCapacity
+	r.inbound, r.outbound = o.inbound, o.outbound
+	r.panicPolicy = o.panicPolicy
+	r.dispatcher = o.dispatcher
+	r.spawnFn, r.spawnArg, r.spawnOpts = fn, arg, opts
+	// open durable mailbox after the name has been locked
+	if o.durableMailboxDir != "" {
+		mailbox, replay, err := openDurableMailbox(o.durableMailboxDir, name)
+		if err != nil {
+			m.unsetNameSpawn(r, name, Halt)
+			m.delActorRef(r.id.id)
+			return nil, err
+		}
+		r.mailbox, r.replay = mailbox, replay
+	}
 	if err := a.StartUp(r, arg); err != nil {
+		r.closeMailbox()
 		m.unsetNameSpawn(r, name, Halt)
 		m.delActorRef(r.id.id)
 		return nil, err
 	}
+	// rebuild state of persistent actor
+	if p, ok := a.(PersistentActor); ok {
+		if err := m.sys.persistence.recoverActor(r, p); err != nil {
+			r.closeMailbox()
+			m.unsetNameSpawn(r, name, Halt)
+			m.delActorRef(r.id.id)
+			return nil, err
+		}
+	}
 	// #4 set running
 	r.setStatus(Running)
 	if err := m.setNameSpawn(r, name, Running); err != nil {
+		r.closeMailbox()
 		m.unsetNameSpawn(r, name, Halt)
 		m.delActorRef(r.id.id)
 		return nil, err
 	}
 	// #5 SPAWN!!!
-	go r.spawn()
+	r.startIdleTimers(&o)
+	r.dispatcher.start(r)
 	return r, nil
 }
 
 func (m *localsManager) shutdownActor(r *LocalRef) {
+	m.haltActor(r, true)
+}
+
+// Actor has exited by panic, its Shutdown method is not called.
+func (m *localsManager) crashActor(r *LocalRef) {
+	// panic during shutting down
+	if !r.checkStatus(Running) {
+		return
+	}
+	m.haltActor(r, false)
+}
+
+func (m *localsManager) haltActor(r *LocalRef, shutdown bool) {
 	name := r.id.name
 	// #1 shutting down
 	m.unsetNameSpawn(r, name, ShuttingDown)
 	r.setStatus(ShuttingDown)
+	close(r.recvClosing)
+	r.recvLock.Lock()
 	close(r.recvCh)
-	r.actor.Shutdown()
+	r.recvLock.Unlock()
+	if shutdown {
+		r.actor.Shutdown()
+	}
 	r.actor = nil
+	r.behaviors = nil
+	r.closeMailbox()
+	r.stopIdleTimers()
 	m.delActorRef(r.id.id)
 	// #2 halt
 	m.unsetNameSpawn(r, name, Halt)
 	r.setStatus(Halt)
+	forwards := r.drainMailbox()
+	if r.passivatedName != "" {
+		m.sys.passivation.halted(r.passivatedName, forwards)
+	}
+	r.runHaltHooks()
 }
 
 // names
@@ -234,12 +323,7 @@ func (m *localsManager) getName(name string) *LocalRef {
 	if id.state != Running {
 		return nil
 	}
-	r, has := m.actors[id.id]
-	if !has {
-		return nil
-	}
-
-	return r
+	return m.getActorRef(id.id)
 }
 
 //
@@ -247,6 +331,7 @@ func (m *localsManager) getName(name string) *LocalRef {
 //
 
 type LocalRef struct {
+	recvLatency int64 // first field, to be 64-bit aligned for atomic operations
 	local       *localsManager
 	id          Id
 	status      Status
@@ -254,9 +339,55 @@ type LocalRef struct {
 	actor       Actor
 	ask         Ask
 	recvCh      chan *message
+	recvLock    sync.RWMutex  // held by senders, recvCh is closed after senders have left
+	recvClosing chan struct{} // closed before recvCh, wakes up blocked senders
 	recvRunning bool
 	recvBeginAt time.Time
 	recvEndAt   time.Time
+	recvPending int32
+	// messages which cannot be put to full recvCh without blocking, see offerMessage
+	overflow     []*message
+	overflowLock sync.Mutex
+	// persistence
+	persistent    PersistentActor
+	persistenceId string
+	persistSeq    uint64
+	journal       Journal
+	// durable mailbox
+	mailbox *durableMailbox
+	replay  []durableMessage
+	// halt hooks
+	haltHooks     map[interface{}]func(ref *LocalRef)
+	haltHooksLock sync.Mutex
+	halted        bool
+	// idle timers and passivation
+	receiveTimeout time.Duration
+	receiveTimer   *time.Timer
+	passivateIdle  time.Duration
+	passivateTimer *time.Timer
+	passivatedName string
+	// spawn function, argument and options, to restart or re-spawn the actor
+	spawnFn   func() Actor
+	spawnArg  interface{}
+	spawnOpts []SpawnOption
+	// stash
+	current       *message
+	stashed       bool
+	stash         []*message
+	stashSize     int32 // atomic, size of stash for other goroutines
+	stashCapacity int
+	unstashed     []*message
+	// behavior stack
+	behaviors []Behavior
+	// interceptors
+	inbound  []InboundInterceptor
+	outbound []OutboundInterceptor
+	// supervision
+	panicPolicy PanicPolicy
+	// dispatcher
+	dispatcher    Dispatcher
+	dispatchState int32
+	dispatchReady bool
 }
 
 func (m *LocalRef) init(local *localsManager, id uint32, a Actor, bufSize int) {
@@ -272,6 +403,7 @@ func (m *LocalRef) init(local *localsManager, id uint32, a Actor, bufSize int) {
 		m.ask = ask
 	}
 	m.recvCh = make(chan *message, bufSize)
+	m.recvClosing = make(chan struct{})
 }
 
 func (m *LocalRef) setStatus(status Status) {
@@ -280,6 +412,44 @@ func (m *LocalRef) setStatus(status Status) {
 	m.statusLock.Unlock()
 }
 
+func (m *LocalRef) closeMailbox() {
+	if m.mailbox != nil {
+		m.mailbox.close()
+	}
+}
+
+// Add a hook with a key, it will be called once the actor has halt.
+// Returns false if the actor has halt already.
+func (m *LocalRef) addHaltHook(key interface{}, fn func(ref *LocalRef)) bool {
+	m.haltHooksLock.Lock()
+	defer m.haltHooksLock.Unlock()
+	if m.halted {
+		return false
+	}
+	if m.haltHooks == nil {
+		m.haltHooks = map[interface{}]func(ref *LocalRef){}
+	}
+	m.haltHooks[key] = fn
+	return true
+}
+
+func (m *LocalRef) delHaltHook(key interface{}) {
+	m.haltHooksLock.Lock()
+	delete(m.haltHooks, key)
+	m.haltHooksLock.Unlock()
+}
+
+func (m *LocalRef) runHaltHooks() {
+	m.haltHooksLock.Lock()
+	hooks := m.haltHooks
+	m.haltHooks = nil
+	m.halted = true
+	m.haltHooksLock.Unlock()
+	for _, fn := range hooks {
+		fn(m)
+	}
+}
+
 func (m *LocalRef) checkStatus(status Status) bool {
 	m.statusLock.RLock()
 	equal := m.status == status
@@ -293,94 +463,246 @@ func (m *LocalRef) logMessageError(err error, msg message) {
 
 func (m *LocalRef) spawn() {
 	// defer handle panic, because handle function might not safe
-	name := m.id.name
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("Actor %s recover from panic and exit\n", name)
-		}
-	}()
+	defer m.recoverCrash()
+	m.started()
+	for m.process(m.nextMessage()) {
+	}
+}
+
+// Actor exits if it panics outside handling a message, such as in Started.
+func (m *LocalRef) recoverCrash() {
+	if r := recover(); r != nil {
+		log.Printf("Actor %s recover from panic and exit\n", m.id.name)
+		m.local.crashActor(m)
+	}
+}
+
+func (m *LocalRef) started() {
 	m.actor.Started()
-	for {
-		// fetch new message
-		msg := <-m.recvCh
-		// mark recv time
-		m.recvBeginAt = time.Now()
-		m.recvRunning = true
-		// handle
-		switch msg.msgType {
-		case msgTypeSend:
-			{
-				m.actor.HandleSend(msg.sender, msg.msgContent)
-			}
-		case msgTypeAsk:
-			{
-				answer := message{
-					sender:     msg.sender,
-					msgSession: msg.msgSession,
-					msgType:    msgTypeAnswer,
-				}
-				if m.ask == nil {
-					answer.msgContent = nil
-					answer.msgError = ErrActorCannotAsk
-					m.local.sessions.handleSession(msg.msgSession, answer)
-					break
+	// replay unacknowledged messages of durable mailbox before other messages
+	for _, msg := range m.replay {
+		m.unstashed = append(m.unstashed, &message{
+			msgType:    msgTypeSend,
+			msgContent: msg.content,
+			mailboxSeq: msg.seq,
+		})
+	}
+	atomic.AddInt32(&m.recvPending, int32(len(m.replay)))
+	m.replay = nil
+}
+
+// Process a message, returns false if the actor has exited.
+func (m *LocalRef) process(msg *message) bool {
+	// mark recv time
+	m.recvBeginAt = time.Now()
+	m.recvRunning = true
+	m.current, m.stashed = msg, false
+	// handle
+	switch msg.msgType {
+	case msgTypeSend, msgTypeAsk:
+		if perr := m.handleMessage(msg); perr != nil && !m.recoverPanic(perr) {
+			m.recvRunning = false
+			m.recvEndAt = time.Now()
+			return false
+		}
+	case msgTypeKill:
+		// TODO: There is a situation that cannot kill an actor:
+		// the previous message is blocking this loop.
+		{
+			m.local.shutdownActor(m)
+			m.recvRunning = false
+			m.recvEndAt = time.Now()
+			return false
+		}
+	case msgTypePassivate:
+		if m.passivate() {
+			m.recvRunning = false
+			m.recvEndAt = time.Now()
+			return false
+		}
+	}
+	m.current = nil
+	m.resetIdleTimers(msg)
+	m.recvRunning = false
+	m.recvEndAt = time.Now()
+	atomic.AddInt32(&m.recvPending, -1)
+	// moving average of handling latency
+	latency := int64(m.recvEndAt.Sub(m.recvBeginAt))
+	atomic.StoreInt64(&m.recvLatency, (atomic.LoadInt64(&m.recvLatency)*7+latency)/8)
+	return true
+}
+
+// Return answer to the asker, answer is a dead letter if the session has gone.
+func (m *LocalRef) answer(answer message) {
+	if !m.local.sessions.handleSession(answer.msgSession, answer) {
+		var target Id
+		if answer.sender != nil {
+			target = answer.sender.Id()
+		}
+		m.local.sys.deadLetter(m, target, answer.msgContent, ErrSessionNotFound)
+	}
+}
+
+// Messages left in mailbox and stash of a halt actor are dead letters, and asks are
+// answered with ErrActorNotRunning. Messages of a passivated actor are returned as
+// forwards to its new incarnation instead.
+func (m *LocalRef) drainMailbox() (forwards []func(lr *LocalRef)) {
+	drain := func(msg *message) {
+		// message of durable mailbox will be replayed by the new incarnation
+		if m.passivatedName != "" && msg.mailboxSeq == 0 &&
+			(msg.msgType == msgTypeSend || msg.msgType == msgTypeAsk) {
+			forwards = append(forwards, func(lr *LocalRef) {
+				if lr == nil || lr.receiving(msg) != nil {
+					m.drainMessage(msg)
 				}
-				answer.msgContent, answer.msgError = m.ask.HandleAsk(msg.sender, msg.msgContent)
-				m.local.sessions.handleSession(msg.msgSession, answer)
-			}
-		case msgTypeKill:
-			// TODO: There is a situation that cannot kill an actor:
-			// the previous message is blocking this loop.
-			{
-				m.local.shutdownActor(m)
-				m.recvRunning = false
-				m.recvEndAt = time.Now()
-				return
-			}
+			})
+			return
 		}
-		m.recvRunning = false
-		m.recvEndAt = time.Now()
+		m.drainMessage(msg)
+	}
+	for _, msg := range m.unstashed {
+		atomic.AddInt32(&m.recvPending, -1)
+		drain(msg)
+	}
+	for _, msg := range m.stash {
+		drain(msg)
 	}
+	m.current, m.stash, m.unstashed = nil, nil, nil
+	atomic.StoreInt32(&m.stashSize, 0)
+	for msg := range m.recvCh {
+		atomic.AddInt32(&m.recvPending, -1)
+		drain(msg)
+	}
+	m.overflowLock.Lock()
+	overflow := m.overflow
+	m.overflow = nil
+	m.overflowLock.Unlock()
+	for _, msg := range overflow {
+		atomic.AddInt32(&m.recvPending, -1)
+		drain(msg)
+	}
+	return forwards
 }
 
-func (m LocalRef) Id() Id {
+func (m *LocalRef) drainMessage(msg *message) {
+	switch msg.msgType {
+	case msgTypeSend:
+		// unacknowledged message of durable mailbox will be replayed
+		if msg.mailboxSeq == 0 {
+			m.local.sys.deadLetter(msg.sender, m.id, msg.msgContent, ErrActorNotRunning)
+		}
+	case msgTypeAsk:
+		m.local.sys.deadLetter(msg.sender, m.id, msg.msgContent, ErrActorNotRunning)
+		m.local.sessions.handleSession(msg.msgSession, message{
+			sender:     msg.sender,
+			msgSession: msg.msgSession,
+			msgType:    msgTypeAnswer,
+			msgError:   ErrActorNotRunning,
+		})
+	}
+}
+
+func (m *LocalRef) Id() Id {
 	return m.id
 }
 
-func (m LocalRef) Status() Status {
+func (m *LocalRef) Status() Status {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
 	return m.status
 }
 
-func (m *LocalRef) receiving(msg *message) (err error) {
-	defer func() {
-		if recover() != nil {
-			log.Println("oops sending closed actor ref")
-			err = ErrActorNotRunning
+// Put message to mailbox, it fails instead of blocking once the actor is shutting
+// down, recvCh is never closed while it is being sent to.
+func (m *LocalRef) receiving(msg *message) error {
+	atomic.AddInt32(&m.recvPending, 1)
+	m.recvLock.RLock()
+	select {
+	case <-m.recvClosing:
+	default:
+		if m.dispatcher.nonBlocking() {
+			m.offerMessage(msg)
+			m.recvLock.RUnlock()
+			m.dispatcher.schedule(m)
+			return nil
 		}
-	}()
-	m.recvCh <- msg
-	return nil
+		select {
+		case m.recvCh <- msg:
+			m.recvLock.RUnlock()
+			m.dispatcher.schedule(m)
+			return nil
+		case <-m.recvClosing:
+		}
+	}
+	m.recvLock.RUnlock()
+	atomic.AddInt32(&m.recvPending, -1)
+	return ErrActorNotRunning
+}
+
+// Moving average of message handling latency.
+func (m *LocalRef) HandleLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.recvLatency))
+}
+
+// Number of messages waiting in mailbox, including the message being handled.
+func (m *LocalRef) MailboxSize() int {
+	return int(atomic.LoadInt32(&m.recvPending))
 }
 
 // #1 PLEASE DO NOT SEND VALUE CONTAINS chan, func, interface{}, pointer and unsafe
 //    it will return ErrMessageValue
 // #2 PLEASE DO NOT MODIFY SENT MESSAGE, no matter send side or receive side
 //    it will affect the state of actor, especially MAP and ARRAY type!
-func (m *LocalRef) Send(sender Ref, msg interface{}) (err error) {
+func (m *LocalRef) Send(sender Ref, msg interface{}) error {
+	return m.local.sys.interceptors.send(&OutboundContext{
+		Sender:  sender,
+		Target:  m,
+		Message: msg,
+	}, func(ctx *OutboundContext) error {
+		return m.sendMessage(ctx.Sender, ctx.Message)
+	})
+}
+
+func (m *LocalRef) sendMessage(sender Ref, msg interface{}) (err error) {
 	//if err := checkMessage(msg, false, 0); err != nil {
 	//	return err
 	//}
 	// TODO critical state
 	if !m.checkStatus(Running) {
+		if ok, err := m.sendReactivated(sender, msg); ok {
+			return err
+		}
+		m.local.sys.deadLetter(sender, m.id, msg, ErrActorNotRunning)
 		return ErrActorNotRunning
 	}
-	return m.receiving(&message{
+	env, content := unwrapEnvelope(msg)
+	// persist message before it is queued, envelope is not persisted
+	var mailboxSeq uint64
+	if m.mailbox != nil {
+		if mailboxSeq, err = m.mailbox.append(content); err != nil {
+			return err
+		}
+	}
+	err = m.receiving(&message{
 		sender:     sender,
 		msgSession: 0,
 		msgType:    msgTypeSend,
-		msgContent: msg,
+		msgContent: content,
 		msgError:   nil,
+		mailboxSeq: mailboxSeq,
+		envelope:   env,
 	})
+	if err != nil {
+		if mailboxSeq > 0 {
+			m.mailbox.ack(mailboxSeq)
+		}
+		// actor has been passivated during sending
+		if ok, err := m.sendReactivated(sender, msg); ok {
+			return err
+		}
+		m.local.sys.deadLetter(sender, m.id, msg, err)
+	}
+	return err
 }
 
 // #1 PLEASE DO NOT SEND VALUE CONTAINS chan, func, interface{}, pointer and unsafe
@@ -388,24 +710,47 @@ func (m *LocalRef) Send(sender Ref, msg interface{}) (err error) {
 // #2 PLEASE DO NOT MODIFY SENT MESSAGE, no matter send side or receive side
 //    it will affect the state of actor, especially MAP and ARRAY type!
 func (m *LocalRef) Ask(sender Ref, ask interface{}, answer interface{}) error {
+	return m.local.sys.interceptors.send(&OutboundContext{
+		Sender:  sender,
+		Target:  m,
+		Message: ask,
+		IsAsk:   true,
+		Answer:  answer,
+	}, func(ctx *OutboundContext) error {
+		return m.askMessage(ctx.Sender, ctx.Message, ctx.Answer)
+	})
+}
+
+func (m *LocalRef) askMessage(sender Ref, ask interface{}, answer interface{}) error {
 	answerValue := reflect.ValueOf(answer)
 	if answerValue.Kind() != reflect.Ptr {
 		return ErrAnswerType
 	}
 	// TODO critical state
 	if !m.checkStatus(Running) {
+		if ok, err := m.askReactivated(sender, ask, answer); ok {
+			return err
+		}
+		m.local.sys.deadLetter(sender, m.id, ask, ErrActorNotRunning)
 		return ErrActorNotRunning
 	}
+	env, content := unwrapEnvelope(ask)
 	s := m.local.sessions.newSession()
 	// sending to self
 	if err := m.receiving(&message{
 		sender:     sender,
 		msgSession: s.id,
 		msgType:    msgTypeAsk,
-		msgContent: ask,
+		msgContent: content,
 		msgError:   nil,
+		envelope:   env,
 	}); err != nil {
 		m.local.sessions.popSession(s.id)
+		// actor has been passivated during asking
+		if ok, err := m.askReactivated(sender, ask, answer); ok {
+			return err
+		}
+		m.local.sys.deadLetter(sender, m.id, ask, err)
 		return err
 	}
 	// wait session to callback
@@ -448,6 +793,9 @@ type message struct {
 	msgType    messageType
 	msgContent interface{}
 	msgError   error
+	mailboxSeq uint64
+	// correlation id, reply-to reference and headers of enveloped message
+	envelope *Envelope
 }
 
 type messageType int
@@ -457,6 +805,8 @@ const (
 	msgTypeAsk    = 1
 	msgTypeAnswer = 2
 	msgTypeKill   = 3
+	// passivate actor if it is idle
+	msgTypePassivate = 4
 )
 
 // session manager
@@ -500,20 +850,23 @@ func (m *sessionsManager) popSession(id uint64) *session {
 	return w
 }
 
-func (m *sessionsManager) handleSession(id uint64, answer message) {
+// Returns false if the session is not found.
+func (m *sessionsManager) handleSession(id uint64, answer message) (handled bool) {
 	s := m.popSession(id)
 	if s == nil {
 		log.Println("SERIOUS! Session not found!", id, answer)
-		return
+		return false
 	}
 
 	// TODO handle remote message
 	defer func() {
 		if recover() != nil {
 			log.Println("oops handle closed session")
+			handled = false
 		}
 	}()
 	s.msgCh <- answer
+	return true
 }
 
 // session wrapper
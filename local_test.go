@@ -699,7 +699,7 @@ func Test_localsManager_spawnActor(t *testing.T) {
 				names:       tt.fields.names,
 				namesLock:   tt.fields.namesLock,
 			}
-			got, err := m.spawnActor(tt.args.fn, tt.args.name, tt.args.arg)
+			got, err := m.spawnActor(tt.args.fn, tt.args.name, tt.args.arg, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("spawnActor() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,222 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Spawn option, messages sent to the actor are persisted to an append-only log in
+// the directory before Send returns, and they are acknowledged after HandleSend
+// returns. Messages which have not been acknowledged, such as messages left by a
+// crashed process, will be replayed on the next SpawnWithName of the same name.
+// Only works with SpawnWithName, and only Send messages are persisted, so messages
+// should be ProtoBuf messages or basic types. Sender of a replayed message is nil.
+// PLEASE NOTE that a message might be handled twice, if the process crashed after
+// handling but before acknowledging it.
+func WithDurableMailbox(dir string) SpawnOption {
+	return func(o *spawnOptions) {
+		o.durableMailboxDir = dir
+	}
+}
+
+const (
+	durableMailboxExt         = ".mailbox"
+	durableMailboxHeaderSize  = 13
+	durableMailboxCompactSize = 1 << 20
+
+	durableRecordMessage byte = 1
+	durableRecordAck     byte = 2
+)
+
+type durableMessage struct {
+	seq     uint64
+	content interface{}
+}
+
+// Append-only log of a durable mailbox.
+// A record is: 1 byte record type, 8 bytes sequence number, 4 bytes length, and
+// serialized message for message record.
+type durableMailbox struct {
+	sync.Mutex
+	path    string
+	file    *os.File
+	seq     uint64
+	pending int
+	size    int
+}
+
+func openDurableMailbox(dir, name string) (*durableMailbox, []durableMessage, error) {
+	if name == "" {
+		return nil, nil, ErrDurableMailboxName
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, err
+	}
+	m := &durableMailbox{
+		path: filepath.Join(dir, url.PathEscape(name)+durableMailboxExt),
+	}
+	messages, err := m.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	// compact, only unacknowledged messages are kept
+	var buf []byte
+	for _, msg := range messages {
+		content, err := marshalContent(msg.content)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf = append(buf, durableRecord(durableRecordMessage, msg.seq, content)...)
+		if msg.seq > m.seq {
+			m.seq = msg.seq
+		}
+	}
+	if err := writeFileSync(m.path, buf); err != nil {
+		return nil, nil, err
+	}
+	m.file, err = os.OpenFile(m.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.pending = len(messages)
+	m.size = len(buf)
+	return m, messages, nil
+}
+
+func durableRecord(recordType byte, seq uint64, content []byte) []byte {
+	record := make([]byte, durableMailboxHeaderSize+len(content))
+	record[0] = recordType
+	binary.BigEndian.PutUint64(record[1:9], seq)
+	binary.BigEndian.PutUint32(record[9:13], uint32(len(content)))
+	copy(record[durableMailboxHeaderSize:], content)
+	return record
+}
+
+// Load unacknowledged messages in order, a broken record at the end of file, which
+// might be caused by crashing during appending, is ignored.
+func (m *durableMailbox) load() ([]durableMessage, error) {
+	buf, err := ioutil.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	unacked := map[uint64]interface{}{}
+	for len(buf) > 0 {
+		if len(buf) < durableMailboxHeaderSize {
+			log.Println("actor durable mailbox truncated,", m.path)
+			break
+		}
+		recordType := buf[0]
+		seq := binary.BigEndian.Uint64(buf[1:9])
+		size := int(binary.BigEndian.Uint32(buf[9:13]))
+		if len(buf) < durableMailboxHeaderSize+size {
+			log.Println("actor durable mailbox truncated,", m.path)
+			break
+		}
+		switch recordType {
+		case durableRecordMessage:
+			content, err := unmarshalContent(buf[durableMailboxHeaderSize : durableMailboxHeaderSize+size])
+			if err != nil {
+				return nil, err
+			}
+			unacked[seq] = content
+		case durableRecordAck:
+			delete(unacked, seq)
+		}
+		buf = buf[durableMailboxHeaderSize+size:]
+	}
+	messages := make([]durableMessage, 0, len(unacked))
+	for seq, content := range unacked {
+		messages = append(messages, durableMessage{seq: seq, content: content})
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].seq < messages[j].seq
+	})
+	return messages, nil
+}
+
+// Persist a message and sync it to disk, returns its sequence number.
+func (m *durableMailbox) append(msg interface{}) (uint64, error) {
+	content, err := marshalContent(msg)
+	if err != nil {
+		return 0, err
+	}
+	m.Lock()
+	defer m.Unlock()
+	if m.file == nil {
+		return 0, ErrActorNotRunning
+	}
+	seq := m.seq + 1
+	if err := m.write(durableRecord(durableRecordMessage, seq, content), true); err != nil {
+		return 0, err
+	}
+	m.seq = seq
+	m.pending++
+	return seq, nil
+}
+
+// Acknowledge a handled message. Ack record is not synced, if it is lost the
+// message will be replayed once more.
+func (m *durableMailbox) ack(seq uint64) {
+	m.Lock()
+	defer m.Unlock()
+	if m.file == nil {
+		return
+	}
+	if err := m.write(durableRecord(durableRecordAck, seq, nil), false); err != nil {
+		log.Println("actor durable mailbox ack error,", m.path, err)
+		return
+	}
+	m.pending--
+	// truncate log once all messages have been acknowledged
+	if m.pending == 0 && m.size > durableMailboxCompactSize {
+		if err := m.file.Truncate(0); err != nil {
+			log.Println("actor durable mailbox compact error,", m.path, err)
+			return
+		}
+		m.size = 0
+	}
+}
+
+// Write a record to the end of log. If it has failed, the partial record is removed,
+// so that records appended later are not broken, or the log is closed if it cannot
+// be removed.
+func (m *durableMailbox) write(record []byte, sync bool) error {
+	_, err := m.file.Write(record)
+	if err == nil && sync {
+		err = m.file.Sync()
+	}
+	if err != nil {
+		if e := m.file.Truncate(int64(m.size)); e != nil {
+			log.Println("actor durable mailbox truncate error,", m.path, e)
+			m.file.Close()
+			m.file = nil
+		}
+		return err
+	}
+	m.size += len(record)
+	return nil
+}
+
+func (m *durableMailbox) close() {
+	m.Lock()
+	defer m.Unlock()
+	if m.file == nil {
+		return
+	}
+	if err := m.file.Close(); err != nil {
+		log.Println("actor durable mailbox close error,", m.path, err)
+	}
+	m.file = nil
+}
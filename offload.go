@@ -0,0 +1,114 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Default number of background workers of LocalRef.Go.
+const DefaultGoWorkers = 64
+
+// Result of work run by LocalRef.Go, it is sent to the actor as a message, with
+// the id returned by LocalRef.Go. Err is a *PanicError if the work panicked.
+type GoResult struct {
+	Id    uint64
+	Value interface{}
+	Err   error
+}
+
+// Set number of background workers of LocalRef.Go of the default system, work
+// which has been running is not affected, surplus workers exit after their work.
+func SetGoWorkers(n int) {
+	defaultSys.SetGoWorkers(n)
+}
+
+// Run blocking work on a bounded background pool, such as blocking I/O, its result
+// is sent to the actor as *GoResult, so state of the actor should only be changed
+// in HandleSend of the result, rather than in the work. Returns id of the work.
+// Work is queued until a worker is free if all workers are busy, Go never blocks.
+// Result is a dead letter if the actor has halt.
+func (m *LocalRef) Go(fn func() (interface{}, error)) uint64 {
+	return m.local.sys.offload.run(m, fn)
+}
+
+// Goroutines are only started for running work, queued work does not hold any
+// goroutine.
+type offloadPool struct {
+	lock    sync.Mutex
+	workers int
+	running int
+	queue   []func()
+	counter uint64
+}
+
+func (m *offloadPool) init(n int) {
+	m.setWorkers(n)
+}
+
+func (m *offloadPool) setWorkers(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	m.lock.Lock()
+	m.workers = n
+	for m.running < m.workers && len(m.queue) > 0 {
+		m.running++
+		go m.work(m.dequeue())
+	}
+	m.lock.Unlock()
+}
+
+func (m *offloadPool) run(self *LocalRef, fn func() (interface{}, error)) uint64 {
+	id := atomic.AddUint64(&m.counter, 1)
+	task := func() {
+		result := &GoResult{Id: id}
+		result.Value, result.Err = runOffload(fn)
+		self.Send(self, result)
+	}
+	m.lock.Lock()
+	if m.running < m.workers {
+		m.running++
+		m.lock.Unlock()
+		go m.work(task)
+		return id
+	}
+	m.queue = append(m.queue, task)
+	m.lock.Unlock()
+	return id
+}
+
+// Run task and then queued tasks, exits if queue is empty or workers have been
+// reduced.
+func (m *offloadPool) work(task func()) {
+	for {
+		task()
+		m.lock.Lock()
+		if len(m.queue) == 0 || m.running > m.workers {
+			m.running--
+			m.lock.Unlock()
+			return
+		}
+		task = m.dequeue()
+		m.lock.Unlock()
+	}
+}
+
+func (m *offloadPool) dequeue() func() {
+	task := m.queue[0]
+	m.queue[0] = nil
+	m.queue = m.queue[1:]
+	return task
+}
+
+func runOffload(fn func() (interface{}, error)) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
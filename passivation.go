@@ -0,0 +1,295 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Message sent to an actor which has not received any message for its receive
+// timeout, see LocalRef.SetReceiveTimeout.
+type ReceiveTimeout struct{}
+
+// Set receive timeout of actor, a ReceiveTimeout message will be sent to the actor
+// once it has not received any message for the duration, and it will be sent again
+// if the actor keeps idle. Zero duration disables receive timeout.
+// It should only be called inside the actor, such as in StartUp and HandleSend.
+func (m *LocalRef) SetReceiveTimeout(d time.Duration) {
+	if m.receiveTimer != nil {
+		m.receiveTimer.Stop()
+		m.receiveTimer = nil
+	}
+	m.receiveTimeout = d
+	if d <= 0 {
+		return
+	}
+	m.receiveTimer = time.AfterFunc(d, func() {
+		m.idleMessage(&message{
+			msgType:    msgTypeSend,
+			msgContent: ReceiveTimeout{},
+		})
+	})
+}
+
+// Spawn option, an actor with name which has not received any message for the idle
+// duration will be shutdown, and it will be re-spawned with the same function,
+// argument and options, once a message is addressed to its name again. Messages to
+// the name are addressed with ByName, remote references, and references of the
+// previous incarnation.
+// Only works with SpawnWithName, and Shutdown method of the actor is called before
+// it is passivated, so it is the chance to save its state.
+func WithPassivation(idle time.Duration) SpawnOption {
+	return func(o *spawnOptions) {
+		o.passivateIdle = idle
+	}
+}
+
+func (m *LocalRef) startIdleTimers(o *spawnOptions) {
+	if o.passivateIdle <= 0 {
+		return
+	}
+	m.passivateIdle = o.passivateIdle
+	m.passivateTimer = time.AfterFunc(o.passivateIdle, func() {
+		m.idleMessage(&message{
+			msgType: msgTypePassivate,
+		})
+	})
+}
+
+// Send message of idle timers to the actor.
+func (m *LocalRef) idleMessage(msg *message) {
+	if m.checkStatus(Running) {
+		m.receiving(msg)
+	}
+}
+
+// Reset idle timers after a message has been handled. Messages of idle timers are
+// not activities of the actor, so that they never delay passivation.
+func (m *LocalRef) resetIdleTimers(msg *message) {
+	if msg.msgType == msgTypePassivate {
+		return
+	}
+	if m.receiveTimer != nil {
+		m.receiveTimer.Reset(m.receiveTimeout)
+	}
+	if _, idle := msg.msgContent.(ReceiveTimeout); idle {
+		return
+	}
+	if m.passivateTimer != nil {
+		m.passivateTimer.Reset(m.passivateIdle)
+	}
+}
+
+func (m *LocalRef) stopIdleTimers() {
+	if m.receiveTimer != nil {
+		m.receiveTimer.Stop()
+	}
+	if m.passivateTimer != nil {
+		m.passivateTimer.Stop()
+	}
+}
+
+// Passivate actor if there is no other message in mailbox, returns true if it has
+// been passivated.
+func (m *LocalRef) passivate() bool {
+	if m.MailboxSize() > 1 {
+		return false
+	}
+	name := m.id.name
+	m.passivatedName = name
+	m.local.sys.passivation.add(name, m.spawnFn, m.spawnArg, m.spawnOpts)
+	m.local.shutdownActor(m)
+	return true
+}
+
+// Returns the reference of the new incarnation of a passivated actor. If the actor
+// has not halt yet, deliver is queued and called with the new incarnation after it
+// has halt, and queued is true.
+func (m *LocalRef) reactivate(deliver func(lr *LocalRef)) (lr *LocalRef, queued bool) {
+	if m.passivatedName == "" {
+		return nil, false
+	}
+	return m.local.sys.passivation.reactivate(m.passivatedName, deliver)
+}
+
+// Send message to the new incarnation of a passivated actor, returns false if the
+// actor has not been passivated.
+func (m *LocalRef) sendReactivated(sender Ref, msg interface{}) (bool, error) {
+	lr, queued := m.reactivate(func(lr *LocalRef) {
+		if lr == nil {
+			m.local.sys.deadLetter(sender, m.id, msg, ErrActorNotRunning)
+			return
+		}
+		lr.sendMessage(sender, msg)
+	})
+	switch {
+	case queued:
+		return true, nil
+	case lr != nil:
+		return true, lr.sendMessage(sender, msg)
+	}
+	return false, nil
+}
+
+// Ask the new incarnation of a passivated actor, returns false if the actor has not
+// been passivated.
+func (m *LocalRef) askReactivated(sender Ref, ask interface{}, answer interface{}) (bool, error) {
+	done := make(chan error, 1)
+	lr, queued := m.reactivate(func(lr *LocalRef) {
+		if lr == nil {
+			m.local.sys.deadLetter(sender, m.id, ask, ErrActorNotRunning)
+			done <- ErrActorNotRunning
+			return
+		}
+		// waiting for the answer should not block other queued messages
+		go func() {
+			done <- lr.askMessage(sender, ask, answer)
+		}()
+	})
+	switch {
+	case queued:
+		return true, <-done
+	case lr != nil:
+		return true, lr.askMessage(sender, ask, answer)
+	}
+	return false, nil
+}
+
+//
+// Passivation
+//
+
+type passivationManager struct {
+	sys     *system
+	lock    sync.Mutex
+	entries map[string]*passivated
+}
+
+type passivated struct {
+	sync.Mutex
+	fn     func() Actor
+	arg    interface{}
+	opts   []SpawnOption
+	haltCh chan struct{}
+	halt   bool
+	// messages sent before the actor has halt, or before they have been flushed
+	pending  []func(lr *LocalRef)
+	flushing bool
+}
+
+func (m *passivationManager) init(sys *system) {
+	m.sys = sys
+	m.entries = map[string]*passivated{}
+}
+
+func (m *passivationManager) add(name string, fn func() Actor, arg interface{}, opts []SpawnOption) {
+	m.lock.Lock()
+	m.entries[name] = &passivated{
+		fn:     fn,
+		arg:    arg,
+		opts:   opts,
+		haltCh: make(chan struct{}),
+	}
+	m.lock.Unlock()
+}
+
+// Passivated actor has halt, its name can be spawned again. Forwards of the messages
+// left in its mailbox and queued messages are delivered to the new incarnation.
+func (m *passivationManager) halted(name string, forwards []func(lr *LocalRef)) {
+	m.lock.Lock()
+	p, has := m.entries[name]
+	m.lock.Unlock()
+	if !has {
+		for _, forward := range forwards {
+			forward(nil)
+		}
+		return
+	}
+	p.Lock()
+	p.halt = true
+	// messages left in mailbox are sent before the queued ones
+	p.pending = append(forwards, p.pending...)
+	p.flushing = len(p.pending) > 0
+	flushing := p.flushing
+	p.Unlock()
+	close(p.haltCh)
+	if flushing {
+		go m.flush(name, p)
+	}
+}
+
+// Returns the new incarnation of a passivated actor, or queues deliver if the actor
+// has not halt, so the actor goroutine is never blocked by its own halt.
+func (m *passivationManager) reactivate(name string, deliver func(lr *LocalRef)) (*LocalRef, bool) {
+	m.lock.Lock()
+	p, has := m.entries[name]
+	m.lock.Unlock()
+	if !has {
+		return nil, false
+	}
+	p.Lock()
+	if !p.halt || p.flushing {
+		p.pending = append(p.pending, deliver)
+		p.Unlock()
+		return nil, true
+	}
+	p.Unlock()
+	return m.activate(name), false
+}
+
+// Re-spawn a passivated actor, and deliver the queued messages in order.
+func (m *passivationManager) flush(name string, p *passivated) {
+	lr := m.activate(name)
+	for {
+		p.Lock()
+		pending := p.pending
+		p.pending = nil
+		if len(pending) == 0 {
+			p.flushing = false
+			p.Unlock()
+			return
+		}
+		p.Unlock()
+		for _, deliver := range pending {
+			deliver(lr)
+		}
+	}
+}
+
+// Re-spawn a passivated actor, returns nil if the name has not been passivated.
+// It waits for the passivated actor to halt.
+func (m *passivationManager) activate(name string) *LocalRef {
+	m.lock.Lock()
+	p, has := m.entries[name]
+	m.lock.Unlock()
+	if !has {
+		return nil
+	}
+	<-p.haltCh
+	p.Lock()
+	defer p.Unlock()
+	// activated by others
+	if lr := m.sys.locals.getName(name); lr != nil {
+		return lr
+	}
+	m.lock.Lock()
+	if m.entries[name] != p {
+		m.lock.Unlock()
+		return m.sys.locals.getName(name)
+	}
+	m.lock.Unlock()
+	lr, err := m.sys.SpawnWithName(p.fn, name, p.arg, p.opts...)
+	if err != nil {
+		log.Println("actor passivation re-spawn error,", name, err)
+		return nil
+	}
+	m.lock.Lock()
+	if m.entries[name] == p {
+		delete(m.entries, name)
+	}
+	m.lock.Unlock()
+	return lr
+}
@@ -0,0 +1,471 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//
+// DEVELOPER TO IMPLEMENT
+//
+
+// PersistentActor interface
+// Implement this interface besides Actor interface, to rebuild the state of an actor
+// from journal when it is starting up. State of a persistent actor should only be
+// changed by events, which are persisted with LocalRef.Persist method, so that state
+// will not be lost even if the actor has not been shutdown properly.
+// Events and snapshots are serialized before they are saved, so they should be
+// ProtoBuf messages, or basic types such as bool, []byte, string, int and float.
+type PersistentActor interface {
+	Actor
+
+	// Unique id of persistent state, it should not be changed between incarnations.
+	// It will be called after StartUp method return nil.
+	PersistenceId() string
+
+	// Change state with an event. It will be called when an event has been persisted
+	// with LocalRef.Persist method, and when events are replaying after StartUp.
+	ApplyEvent(event interface{})
+
+	// Return a snapshot of current state, it is called by LocalRef.SaveSnapshot method.
+	Snapshot() (interface{}, error)
+
+	// Restore state from a snapshot, it is called before events replaying.
+	Restore(snapshot interface{}) error
+}
+
+//
+// Journal
+//
+
+// Implement this interface to store events and snapshots of persistent actors.
+// Sequence number of events of a persistence id starts from 1 and auto increase.
+type Journal interface {
+	// Append an event of a persistence id.
+	Append(persistenceId string, seq uint64, event interface{}) error
+	// Replay events of a persistence id whose sequence number is greater than fromSeq in order.
+	Replay(persistenceId string, fromSeq uint64, fn func(seq uint64, event interface{}) error) error
+	// Delete events of a persistence id whose sequence number is not greater than toSeq.
+	Delete(persistenceId string, toSeq uint64) error
+	// Save snapshot of a persistence id, with sequence number of the last event it contains.
+	SaveSnapshot(persistenceId string, seq uint64, snapshot interface{}) error
+	// Load latest snapshot of a persistence id, snapshot is nil if it has no snapshot.
+	LoadSnapshot(persistenceId string) (seq uint64, snapshot interface{}, err error)
+}
+
+// Set journal of the default system, MemoryJournal is used by default.
+// Persistent actors which are running should keep using the previous journal.
+func SetJournal(journal Journal) {
+	defaultSys.SetJournal(journal)
+}
+
+type persistenceManager struct {
+	journal     Journal
+	journalLock sync.RWMutex
+}
+
+func (m *persistenceManager) init() {
+	m.journal = NewMemoryJournal()
+}
+
+func (m *persistenceManager) setJournal(journal Journal) {
+	m.journalLock.Lock()
+	m.journal = journal
+	m.journalLock.Unlock()
+}
+
+func (m *persistenceManager) getJournal() Journal {
+	m.journalLock.RLock()
+	defer m.journalLock.RUnlock()
+	return m.journal
+}
+
+// Rebuild state of a persistent actor from its snapshot and events.
+func (m *persistenceManager) recoverActor(r *LocalRef, p PersistentActor) error {
+	journal := m.getJournal()
+	id := p.PersistenceId()
+	seq, snapshot, err := journal.LoadSnapshot(id)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		if err := p.Restore(snapshot); err != nil {
+			return err
+		}
+	}
+	err = journal.Replay(id, seq, func(eventSeq uint64, event interface{}) error {
+		p.ApplyEvent(event)
+		seq = eventSeq
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.persistent = p
+	r.persistenceId = id
+	r.persistSeq = seq
+	r.journal = journal
+	return nil
+}
+
+//
+// LocalRef persistence
+//
+
+// Persist an event to journal, then apply it to the state of actor.
+// It should only be called inside the actor, such as in HandleSend and HandleAsk.
+// State of actor will not be changed if it returns error.
+func (m *LocalRef) Persist(event interface{}) error {
+	if m.persistent == nil {
+		return ErrNotPersistentActor
+	}
+	if err := m.journal.Append(m.persistenceId, m.persistSeq+1, event); err != nil {
+		return err
+	}
+	m.persistSeq++
+	m.persistent.ApplyEvent(event)
+	return nil
+}
+
+// Save snapshot of the state of actor, and delete events it contains from journal.
+// It should only be called inside the actor, such as in HandleSend and HandleAsk.
+func (m *LocalRef) SaveSnapshot() error {
+	if m.persistent == nil {
+		return ErrNotPersistentActor
+	}
+	snapshot, err := m.persistent.Snapshot()
+	if err != nil {
+		return err
+	}
+	if err := m.journal.SaveSnapshot(m.persistenceId, m.persistSeq, snapshot); err != nil {
+		return err
+	}
+	if err := m.journal.Delete(m.persistenceId, m.persistSeq); err != nil {
+		log.Println("actor persistence delete events error,", m.persistenceId, err)
+	}
+	return nil
+}
+
+// Sequence number of the last persisted event of actor.
+func (m *LocalRef) PersistSeq() uint64 {
+	return m.persistSeq
+}
+
+//
+// Memory Journal
+//
+
+type journalEvent struct {
+	seq   uint64
+	event interface{}
+}
+
+type journalSnapshot struct {
+	seq      uint64
+	snapshot interface{}
+}
+
+// Memory journal, events and snapshots will be lost if process exits.
+type MemoryJournal struct {
+	sync.Mutex
+	events    map[string][]journalEvent
+	snapshots map[string]journalSnapshot
+}
+
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{
+		events:    map[string][]journalEvent{},
+		snapshots: map[string]journalSnapshot{},
+	}
+}
+
+func (m *MemoryJournal) Append(persistenceId string, seq uint64, event interface{}) error {
+	m.Lock()
+	m.events[persistenceId] = append(m.events[persistenceId], journalEvent{seq: seq, event: event})
+	m.Unlock()
+	return nil
+}
+
+func (m *MemoryJournal) Replay(persistenceId string, fromSeq uint64, fn func(seq uint64, event interface{}) error) error {
+	m.Lock()
+	events := append([]journalEvent(nil), m.events[persistenceId]...)
+	m.Unlock()
+	for _, e := range events {
+		if e.seq <= fromSeq {
+			continue
+		}
+		if err := fn(e.seq, e.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryJournal) Delete(persistenceId string, toSeq uint64) error {
+	m.Lock()
+	defer m.Unlock()
+	var events []journalEvent
+	for _, e := range m.events[persistenceId] {
+		if e.seq > toSeq {
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		delete(m.events, persistenceId)
+	} else {
+		m.events[persistenceId] = events
+	}
+	return nil
+}
+
+func (m *MemoryJournal) SaveSnapshot(persistenceId string, seq uint64, snapshot interface{}) error {
+	m.Lock()
+	m.snapshots[persistenceId] = journalSnapshot{seq: seq, snapshot: snapshot}
+	m.Unlock()
+	return nil
+}
+
+func (m *MemoryJournal) LoadSnapshot(persistenceId string) (uint64, interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+	s, has := m.snapshots[persistenceId]
+	if !has {
+		return 0, nil, nil
+	}
+	return s.seq, s.snapshot, nil
+}
+
+//
+// File Journal
+//
+
+// File journal, events of a persistence id are appended to a journal file, and each
+// event is synced to disk before it is applied. Snapshot is saved as another file.
+// A journal file record is: 8 bytes sequence number, 4 bytes length, and serialized event.
+// A torn record at the end of file, left by a failed write or crash, is truncated
+// before the file is appended again.
+type FileJournal struct {
+	sync.Mutex
+	dir   string
+	files map[string]*os.File
+}
+
+const (
+	fileJournalEventsExt   = ".journal"
+	fileJournalSnapshotExt = ".snapshot"
+	fileJournalHeaderSize  = 12
+)
+
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileJournal{
+		dir:   dir,
+		files: map[string]*os.File{},
+	}, nil
+}
+
+func (m *FileJournal) path(persistenceId, ext string) string {
+	return filepath.Join(m.dir, url.PathEscape(persistenceId)+ext)
+}
+
+// Close all opened journal files.
+func (m *FileJournal) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	var err error
+	for id, f := range m.files {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(m.files, id)
+	}
+	return err
+}
+
+func (m *FileJournal) Append(persistenceId string, seq uint64, event interface{}) error {
+	buf, err := marshalContent(event)
+	if err != nil {
+		return err
+	}
+	record := fileJournalRecord(seq, buf)
+
+	m.Lock()
+	defer m.Unlock()
+	f, err := m.openFile(persistenceId)
+	if err != nil {
+		return err
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(record)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		// remove the partial record, or reopen and repair the file next time
+		if e := f.Truncate(offset); e != nil {
+			f.Close()
+			delete(m.files, persistenceId)
+		}
+		return err
+	}
+	return nil
+}
+
+// Open journal file for appending, its torn tail is truncated when it is opened.
+func (m *FileJournal) openFile(persistenceId string) (*os.File, error) {
+	if f, has := m.files[persistenceId]; has {
+		return f, nil
+	}
+	if _, err := m.readRecords(persistenceId); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(m.path(persistenceId, fileJournalEventsExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	m.files[persistenceId] = f
+	return f, nil
+}
+
+func fileJournalRecord(seq uint64, content []byte) []byte {
+	record := make([]byte, fileJournalHeaderSize+len(content))
+	binary.BigEndian.PutUint64(record[0:8], seq)
+	binary.BigEndian.PutUint32(record[8:12], uint32(len(content)))
+	copy(record[fileJournalHeaderSize:], content)
+	return record
+}
+
+// Read all records of a journal file, a broken record at the end of file, which
+// might be caused by crashing during appending, is truncated.
+func (m *FileJournal) readRecords(persistenceId string) ([]journalEvent, error) {
+	path := m.path(persistenceId, fileJournalEventsExt)
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		events []journalEvent
+		offset int
+	)
+	for offset < len(buf) {
+		rest := buf[offset:]
+		if len(rest) < fileJournalHeaderSize {
+			break
+		}
+		seq := binary.BigEndian.Uint64(rest[0:8])
+		size := int(binary.BigEndian.Uint32(rest[8:12]))
+		if len(rest) < fileJournalHeaderSize+size {
+			break
+		}
+		event, err := unmarshalContent(rest[fileJournalHeaderSize : fileJournalHeaderSize+size])
+		if err != nil {
+			// only the last record might be torn
+			if len(rest) == fileJournalHeaderSize+size {
+				break
+			}
+			return nil, err
+		}
+		events = append(events, journalEvent{seq: seq, event: event})
+		offset += fileJournalHeaderSize + size
+	}
+	if offset < len(buf) {
+		log.Println("actor persistence journal torn record truncated,", persistenceId, len(buf)-offset)
+		if err := os.Truncate(path, int64(offset)); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (m *FileJournal) Replay(persistenceId string, fromSeq uint64, fn func(seq uint64, event interface{}) error) error {
+	m.Lock()
+	events, err := m.readRecords(persistenceId)
+	m.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.seq <= fromSeq {
+			continue
+		}
+		if err := fn(e.seq, e.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *FileJournal) Delete(persistenceId string, toSeq uint64) error {
+	m.Lock()
+	defer m.Unlock()
+	events, err := m.readRecords(persistenceId)
+	if err != nil {
+		return err
+	}
+	if f, has := m.files[persistenceId]; has {
+		f.Close()
+		delete(m.files, persistenceId)
+	}
+	var buf []byte
+	for _, e := range events {
+		if e.seq <= toSeq {
+			continue
+		}
+		content, err := marshalContent(e.event)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, fileJournalRecord(e.seq, content)...)
+	}
+	return writeFileSync(m.path(persistenceId, fileJournalEventsExt), buf)
+}
+
+func (m *FileJournal) SaveSnapshot(persistenceId string, seq uint64, snapshot interface{}) error {
+	content, err := marshalContent(snapshot)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 8+len(content))
+	binary.BigEndian.PutUint64(buf[0:8], seq)
+	copy(buf[8:], content)
+	m.Lock()
+	defer m.Unlock()
+	return writeFileSync(m.path(persistenceId, fileJournalSnapshotExt), buf)
+}
+
+func (m *FileJournal) LoadSnapshot(persistenceId string) (uint64, interface{}, error) {
+	m.Lock()
+	buf, err := ioutil.ReadFile(m.path(persistenceId, fileJournalSnapshotExt))
+	m.Unlock()
+	if os.IsNotExist(err) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(buf) < 8 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	snapshot, err := unmarshalContent(buf[8:])
+	if err != nil {
+		return 0, nil, err
+	}
+	return binary.BigEndian.Uint64(buf[0:8]), snapshot, nil
+}
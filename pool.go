@@ -0,0 +1,198 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	PoolDefaultScaleInterval  = 1 * time.Second
+	PoolDefaultScaleUpMailbox = 2
+	PoolDefaultScaleDownIdle  = 10 * time.Second
+)
+
+type PoolConfig struct {
+	// Minimum and maximum number of workers.
+	Min, Max int
+	// Routing strategy of workers, RouteRoundRobin by default.
+	Strategy RouterStrategy
+	// Interval of checking workers, crashed workers are replaced and pool is resized.
+	ScaleInterval time.Duration
+	// Grow pool by one worker, if average mailbox size of workers reaches it.
+	ScaleUpMailbox int
+	// Grow pool by one worker, if average handling latency of workers reaches it,
+	// zero means latency is not considered. Latency of a worker with an empty
+	// mailbox is counted as zero, its last latency is stale.
+	ScaleUpLatency time.Duration
+	// Shrink pool by one worker, if all workers have been idle for this duration.
+	ScaleDownIdle time.Duration
+	// Spawn options of workers.
+	Options []SpawnOption
+}
+
+//
+// Pool
+//
+
+// Pool is a router of workers, which are spawned with the same function and argument.
+// Pool grows or shrinks within bounds with the load of workers.
+type PoolRef struct {
+	*RouterRef
+	sys       *system
+	fn        func() Actor
+	arg       interface{}
+	config    PoolConfig
+	lock      sync.Mutex
+	workers   []*LocalRef
+	idleSince time.Time
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+}
+
+// Spawns a pool with minimum number of workers.
+func SpawnPool(fn func() Actor, arg interface{}, config PoolConfig) (*PoolRef, error) {
+	return defaultSys.SpawnPool(fn, arg, config)
+}
+
+func (m *system) SpawnPool(fn func() Actor, arg interface{}, config PoolConfig) (*PoolRef, error) {
+	if config.Min <= 0 || config.Max < config.Min {
+		return nil, ErrArgument
+	}
+	if config.ScaleInterval <= 0 {
+		config.ScaleInterval = PoolDefaultScaleInterval
+	}
+	if config.ScaleUpMailbox <= 0 {
+		config.ScaleUpMailbox = PoolDefaultScaleUpMailbox
+	}
+	if config.ScaleDownIdle <= 0 {
+		config.ScaleDownIdle = PoolDefaultScaleDownIdle
+	}
+	p := &PoolRef{
+		RouterRef: NewRouter(config.Strategy),
+		sys:       m,
+		fn:        fn,
+		arg:       arg,
+		config:    config,
+		idleSince: time.Now(),
+		stopCh:    make(chan struct{}),
+	}
+	for i := 0; i < config.Min; i++ {
+		if err := p.grow(); err != nil {
+			p.Shutdown(nil)
+			return nil, err
+		}
+	}
+	go p.monitor()
+	return p, nil
+}
+
+// Number of workers.
+func (m *PoolRef) Size() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.workers)
+}
+
+// Stop resizing, and shutdown all workers.
+func (m *PoolRef) Shutdown(sender Ref) error {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	return m.RouterRef.Shutdown(sender)
+}
+
+func (m *PoolRef) grow() error {
+	w, err := m.sys.Spawn(m.fn, m.arg, m.config.Options...)
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	m.workers = append(m.workers, w)
+	m.lock.Unlock()
+	m.AddRoutee(w)
+	return nil
+}
+
+// Remove a worker from router before shutting it down, messages have been queued
+// are still handled.
+func (m *PoolRef) shrink() {
+	m.lock.Lock()
+	w := m.workers[len(m.workers)-1]
+	m.workers = m.workers[:len(m.workers)-1]
+	m.lock.Unlock()
+	m.RemoveRoutee(w.Id())
+	w.Shutdown(nil)
+}
+
+func (m *PoolRef) monitor() {
+	ticker := time.NewTicker(m.config.ScaleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.replace()
+			m.resize()
+		}
+	}
+}
+
+// Replace crashed workers.
+func (m *PoolRef) replace() {
+	m.lock.Lock()
+	var halted []*LocalRef
+	workers := m.workers[:0]
+	for _, w := range m.workers {
+		if w.Status() == Running {
+			workers = append(workers, w)
+		} else {
+			halted = append(halted, w)
+		}
+	}
+	m.workers = workers
+	m.lock.Unlock()
+	for _, w := range halted {
+		m.RemoveRoutee(w.Id())
+		if err := m.grow(); err != nil {
+			log.Println("actor pool replace worker error,", err)
+		}
+	}
+}
+
+func (m *PoolRef) resize() {
+	m.lock.Lock()
+	workers := append([]*LocalRef{}, m.workers...)
+	m.lock.Unlock()
+	if len(workers) == 0 {
+		return
+	}
+	mailbox, latency := 0, time.Duration(0)
+	for _, w := range workers {
+		size := w.MailboxSize()
+		if size == 0 {
+			continue
+		}
+		mailbox += size
+		latency += w.HandleLatency()
+	}
+	n := len(workers)
+	busy := mailbox/n >= m.config.ScaleUpMailbox ||
+		(m.config.ScaleUpLatency > 0 && latency/time.Duration(n) >= m.config.ScaleUpLatency)
+	switch {
+	case busy && n < m.config.Max:
+		if err := m.grow(); err != nil {
+			log.Println("actor pool grow error,", err)
+		}
+		m.idleSince = time.Now()
+	case mailbox > 0:
+		m.idleSince = time.Now()
+	case n > m.config.Min && time.Since(m.idleSince) >= m.config.ScaleDownIdle:
+		m.shrink()
+		m.idleSince = time.Now()
+	}
+}
@@ -0,0 +1,479 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Delivery modes of reliable remote Send.
+const (
+	// Default mode, a message might be lost if Send has timed out.
+	DeliveryAtMostOnce = Delivery_AtMostOnce
+	// A message will be retried until it has been acknowledged, it might be
+	// delivered more than once.
+	DeliveryAtLeastOnce = Delivery_AtLeastOnce
+	// A message will be retried until it has been acknowledged or the deduplication
+	// window has passed, receiver drops duplicated messages which have been
+	// delivered in the window.
+	DeliveryExactlyOnce = Delivery_ExactlyOnce
+)
+
+const (
+	ReliableDefaultRetryInterval = 1 * time.Second
+	ReliableDefaultDedupWindow   = 10 * time.Minute
+)
+
+type ReliableConfig struct {
+	// Store persists unacknowledged messages, MemoryReliableStore is used if nil.
+	Store ReliableStore
+	// Interval between retries of an unacknowledged message.
+	RetryInterval time.Duration
+	// How long a delivered message id is remembered by the receiver. A message of
+	// DeliveryExactlyOnce is not retried once the window has passed since it was
+	// sent, it becomes a dead letter with ErrReliableExpired. The window should be
+	// the same on both nodes.
+	// PLEASE NOTE that delivered message ids are kept in memory, a message which is
+	// retried after the receiver has restarted might be delivered again.
+	DedupWindow time.Duration
+}
+
+//
+// Reliable Send
+//
+
+// Enable reliable delivery of actor.Remote. Messages in the store which have not
+// been acknowledged before, such as messages left by last running process, will
+// be retried once the connections of their nodes have been dialed.
+func (m *remoteManager) EnableReliable(config ReliableConfig) error {
+	if !m.ready {
+		return ErrRemoteManagerNotReady
+	}
+	return m.reliable.enable(m, config)
+}
+
+// Disable reliable delivery of actor.Remote, retrying stops, and messages which
+// have not been acknowledged are kept in the store. It returns after the store is
+// no longer used by retrying, then the store can be removed, or enabled again.
+func (m *remoteManager) DisableReliable() {
+	m.reliable.disable()
+}
+
+// Send message reliably, the message will be persisted before it is sent.
+// Returns nil if the message has been acknowledged, or ErrRemotePending if the
+// message has been persisted but not acknowledged yet, it will be retried in
+// the background until it is acknowledged.
+// PLEASE NOTE that only with DeliveryExactlyOnce, a retried message is
+// deduplicated by the receiver.
+func (m *RemoteRef) SendReliable(sender Ref, msg interface{}, delivery Delivery) error {
+	if delivery == Delivery_AtMostOnce {
+		return m.SendWithAckMode(sender, msg, AckMode_AckEach)
+	}
+	if m.node == nil {
+		return ErrRemoteConnNotFound
+	}
+	if !m.node.global.reliable.enabled() {
+		return ErrReliableNotEnabled
+	}
+	req, err := newSendNameRequest(sender, m.id.name, msg)
+	if err != nil {
+		return err
+	}
+	req.AckMode = AckMode_AckEach
+	req.Delivery = delivery
+	return m.node.global.reliable.send(m.node, req)
+}
+
+type reliableManager struct {
+	sync.Mutex
+	remote        *remoteManager
+	store         ReliableStore
+	retryInterval time.Duration
+	dedupWindow   time.Duration
+	sending       map[string]bool
+	idPrefix      string
+	idCount       uint64
+	stopCh        chan struct{}
+	loopWait      sync.WaitGroup
+	// receiver side
+	dedup dedupWindow
+}
+
+func (m *reliableManager) init() {
+	m.dedup.init(ReliableDefaultDedupWindow)
+}
+
+func (m *reliableManager) enable(remote *remoteManager, config ReliableConfig) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.store != nil {
+		return ErrReliableEnabled
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryReliableStore()
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = ReliableDefaultRetryInterval
+	}
+	if config.DedupWindow <= 0 {
+		config.DedupWindow = ReliableDefaultDedupWindow
+	}
+	m.dedup.setWindow(config.DedupWindow)
+	m.remote = remote
+	m.store = config.Store
+	m.retryInterval = config.RetryInterval
+	m.dedupWindow = config.DedupWindow
+	m.sending = map[string]bool{}
+	m.idPrefix = fmt.Sprintf("%d-%d-", remote.nodeId, time.Now().UnixNano())
+	m.stopCh = make(chan struct{})
+	m.loopWait.Add(1)
+	go m.retryLoop(m.stopCh)
+	return nil
+}
+
+func (m *reliableManager) disable() {
+	m.Lock()
+	if m.store == nil {
+		m.Unlock()
+		return
+	}
+	close(m.stopCh)
+	m.store = nil
+	m.Unlock()
+	m.loopWait.Wait()
+}
+
+func (m *reliableManager) enabled() bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.store != nil
+}
+
+func (m *reliableManager) newMessageId() string {
+	return m.idPrefix + fmt.Sprint(atomic.AddUint64(&m.idCount, 1))
+}
+
+func (m *reliableManager) send(node *outNode, req *SendName_Request) error {
+	req.MessageId = m.newMessageId()
+	buf, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	record := &ReliableRecord{
+		Id:        req.MessageId,
+		NodeId:    node.nodeId,
+		Request:   buf,
+		CreatedAt: time.Now(),
+	}
+	m.Lock()
+	// it might have been disabled since SendReliable checked
+	store := m.store
+	if store == nil {
+		m.Unlock()
+		return ErrReliableNotEnabled
+	}
+	m.sending[record.Id] = true
+	m.Unlock()
+	if err := store.Save(record); err != nil {
+		m.Lock()
+		delete(m.sending, record.Id)
+		m.Unlock()
+		return err
+	}
+	if m.attempt(node, record, req) {
+		return nil
+	}
+	return ErrRemotePending
+}
+
+// Send a persisted message once, delete it from store if it has been acknowledged.
+func (m *reliableManager) attempt(node *outNode, record *ReliableRecord, req *SendName_Request) (acked bool) {
+	defer func() {
+		m.Lock()
+		delete(m.sending, record.Id)
+		m.Unlock()
+	}()
+	if err := sendName(node, req); err != nil {
+		log.Println("actor.Remote reliable send not acknowledged,", record.Id, err)
+		return false
+	}
+	m.Lock()
+	store := m.store
+	m.Unlock()
+	if store == nil {
+		return true
+	}
+	if err := store.Delete(record.Id); err != nil {
+		log.Println("actor.Remote reliable store delete error,", record.Id, err)
+	}
+	return true
+}
+
+func (m *reliableManager) retryLoop(stopCh chan struct{}) {
+	defer m.loopWait.Done()
+	ticker := time.NewTicker(m.retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			m.retry()
+		}
+	}
+}
+
+func (m *reliableManager) retry() {
+	m.Lock()
+	store, window := m.store, m.dedupWindow
+	m.Unlock()
+	if store == nil {
+		return
+	}
+	records, err := store.Load()
+	if err != nil {
+		log.Println("actor.Remote reliable store load error,", err)
+		return
+	}
+	for _, record := range records {
+		if time.Since(record.CreatedAt) < m.retryInterval {
+			continue
+		}
+		node := m.remote.conn.getOutConn(record.NodeId)
+		if node == nil || !node.isReady() {
+			continue
+		}
+		req := &SendName_Request{}
+		if err := proto.Unmarshal(record.Request, req); err != nil {
+			log.Println("actor.Remote reliable record invalid,", record.Id, err)
+			continue
+		}
+		if req.Delivery == Delivery_ExactlyOnce && time.Since(record.CreatedAt) > window {
+			m.expire(store, record, req)
+			continue
+		}
+		m.Lock()
+		if m.sending[record.Id] {
+			m.Unlock()
+			continue
+		}
+		m.sending[record.Id] = true
+		m.Unlock()
+		go m.attempt(node, record, req)
+	}
+}
+
+// A message of DeliveryExactlyOnce is no longer retried once the deduplication
+// window has passed, since the receiver might have forgotten it.
+func (m *reliableManager) expire(store ReliableStore, record *ReliableRecord, req *SendName_Request) {
+	if err := store.Delete(record.Id); err != nil {
+		log.Println("actor.Remote reliable store delete error,", record.Id, err)
+		return
+	}
+	content, err := decodeContent(req.SendData)
+	if err != nil {
+		content = req.SendData
+	}
+	m.remote.sys.deadLetter(nil, Id{node: record.NodeId, name: req.ToName}, content, ErrReliableExpired)
+}
+
+//
+// Deduplication
+//
+
+// Receiver remembers the ids of delivered messages during the window.
+type dedupWindow struct {
+	sync.Mutex
+	window    time.Duration
+	delivered map[string]time.Time
+	cleanedAt time.Time
+}
+
+func (m *dedupWindow) init(window time.Duration) {
+	m.window = window
+	m.delivered = map[string]time.Time{}
+	m.cleanedAt = time.Now()
+}
+
+func (m *dedupWindow) setWindow(window time.Duration) {
+	m.Lock()
+	m.window = window
+	m.Unlock()
+}
+
+// Mark message as delivering, returns false if it has been delivered.
+func (m *dedupWindow) mark(nodeId uint32, messageId string) bool {
+	key := fmt.Sprintf("%d/%s", nodeId, messageId)
+	now := time.Now()
+	m.Lock()
+	defer m.Unlock()
+	if now.Sub(m.cleanedAt) > m.window {
+		for k, at := range m.delivered {
+			if now.Sub(at) > m.window {
+				delete(m.delivered, k)
+			}
+		}
+		m.cleanedAt = now
+	}
+	if at, has := m.delivered[key]; has && now.Sub(at) <= m.window {
+		return false
+	}
+	m.delivered[key] = now
+	return true
+}
+
+// Unmark message if it has failed to deliver, so that it can be retried.
+func (m *dedupWindow) unmark(nodeId uint32, messageId string) {
+	key := fmt.Sprintf("%d/%s", nodeId, messageId)
+	m.Lock()
+	delete(m.delivered, key)
+	m.Unlock()
+}
+
+//
+// Reliable Store
+//
+
+// A message which has not been acknowledged.
+type ReliableRecord struct {
+	Id        string
+	NodeId    uint32
+	Request   []byte // Marshaled SendName_Request
+	CreatedAt time.Time
+}
+
+// Implement this interface to persist unacknowledged messages.
+type ReliableStore interface {
+	Save(record *ReliableRecord) error
+	Delete(id string) error
+	Load() ([]*ReliableRecord, error)
+}
+
+// Memory store, unacknowledged messages will be lost if process exits.
+type MemoryReliableStore struct {
+	sync.Mutex
+	records map[string]*ReliableRecord
+}
+
+func NewMemoryReliableStore() *MemoryReliableStore {
+	return &MemoryReliableStore{
+		records: map[string]*ReliableRecord{},
+	}
+}
+
+func (m *MemoryReliableStore) Save(record *ReliableRecord) error {
+	m.Lock()
+	m.records[record.Id] = record
+	m.Unlock()
+	return nil
+}
+
+func (m *MemoryReliableStore) Delete(id string) error {
+	m.Lock()
+	delete(m.records, id)
+	m.Unlock()
+	return nil
+}
+
+func (m *MemoryReliableStore) Load() ([]*ReliableRecord, error) {
+	m.Lock()
+	defer m.Unlock()
+	records := make([]*ReliableRecord, 0, len(m.records))
+	for _, record := range m.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// File store, each unacknowledged message is saved as a file in the directory,
+// and it is synced to disk before Send.
+type FileReliableStore struct {
+	dir string
+}
+
+const fileReliableStoreExt = ".msg"
+
+func NewFileReliableStore(dir string) (*FileReliableStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileReliableStore{
+		dir: dir,
+	}, nil
+}
+
+func (m *FileReliableStore) path(id string) string {
+	return filepath.Join(m.dir, id+fileReliableStoreExt)
+}
+
+func (m *FileReliableStore) Save(record *ReliableRecord) error {
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return writeFileSync(m.path(record.Id), buf)
+}
+
+func (m *FileReliableStore) Delete(id string) error {
+	err := os.Remove(m.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (m *FileReliableStore) Load() ([]*ReliableRecord, error) {
+	files, err := ioutil.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	var records []*ReliableRecord
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileReliableStoreExt) {
+			continue
+		}
+		buf, err := ioutil.ReadFile(filepath.Join(m.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		record := &ReliableRecord{}
+		if err := json.Unmarshal(buf, record); err != nil {
+			log.Println("actor.Remote file reliable store record invalid,", file.Name(), err)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Write to a temporary file, sync and then rename, so that a file is never half written.
+func writeFileSync(path string, buf []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -16,15 +16,17 @@ var (
 )
 
 type remoteManager struct {
-	sys    *system
-	ready  bool
-	nodeId uint32
-	conn   conn
+	sys      *system
+	ready    bool
+	nodeId   uint32
+	conn     conn
+	reliable reliableManager
 }
 
 func (m *remoteManager) init(sys *system) {
 	m.sys = sys
 	m.ready = false
+	m.reliable.init()
 }
 
 //
@@ -56,6 +58,9 @@ func (m *RemoteConn) ByName(name string) (*RemoteRef, error) {
 	select {
 	case respMsg, more := <-w.respCh:
 		{
+			if w.err != nil {
+				return nil, w.err
+			}
 			if !more || respMsg == nil || respMsg.GetGetName() == nil || respMsg.GetGetName().GetResp() == nil {
 				return nil, ErrRemoteResponse
 			}
@@ -74,7 +79,7 @@ func (m *RemoteConn) ByName(name string) (*RemoteRef, error) {
 		}
 	case <-time.After(requestTimeout):
 		{
-			w.canceled = true
+			w.cancel()
 			return nil, ErrRemoteTimeout
 		}
 	}
@@ -84,26 +89,104 @@ func (m *RemoteConn) ByName(name string) (*RemoteRef, error) {
 // Remote Ref
 //
 
+// Acknowledgment modes of remote Send.
+const (
+	// Default mode, Send waits for the acknowledgment of each message.
+	SendAckEach = AckMode_AckEach
+	// Fire-and-forget, Send returns after the message has been queued to the
+	// connection, the receiver will not reply.
+	SendAckNone = AckMode_AckNone
+	// At-least-once, Send returns after the message has been queued to the
+	// connection, the receiver acknowledges messages in batches, messages which
+	// have not been acknowledged in time will be resent. Messages which have not
+	// been acknowledged when the connection is lost are resent after the node has
+	// been dialed again, they are lost if it is never dialed again.
+	SendAckBatch = AckMode_AckBatch
+)
+
 type RemoteRef struct {
-	id   Id
-	node *outNode // todo nil
+	id      Id
+	node    *outNode // todo nil
+	in      *inNode  // incoming connection which the sender has sent from
+	ackMode AckMode
 }
 
+// Set the default acknowledgment mode of Send via this reference.
+func (m *RemoteRef) SetAckMode(mode AckMode) {
+	m.ackMode = mode
+}
+
+// Remote actor is treated as running, as long as the connection of its node is ready.
 func (m RemoteRef) Status() Status {
-	return Halt
+	if m.in != nil && !m.in.isClosed() {
+		return Running
+	}
+	if m.node == nil || !m.node.isReady() {
+		return Halt
+	}
+	return Running
 }
 
 func (m RemoteRef) Id() Id {
 	return m.id
 }
 
+func (m *RemoteRef) sys() *system {
+	if m.in != nil && m.in.global != nil {
+		return m.in.global.sys
+	}
+	if m.node == nil || m.node.global == nil {
+		return defaultSys
+	}
+	return m.node.global.sys
+}
+
 func (m *RemoteRef) Send(sender Ref, msg interface{}) error {
+	return m.SendWithAckMode(sender, msg, m.ackMode)
+}
+
+// Send message with specific acknowledgment mode.
+func (m *RemoteRef) SendWithAckMode(sender Ref, msg interface{}, mode AckMode) error {
+	return m.sys().interceptors.send(&OutboundContext{
+		Sender:  sender,
+		Target:  m,
+		Message: msg,
+	}, func(ctx *OutboundContext) error {
+		return m.sendWithAckMode(ctx.Sender, ctx.Message, mode)
+	})
+}
+
+// Reply to a sender of incoming message is sent back via the incoming connection
+// it has sent from, only if it is fire-and-forget, since the sender's node does not
+// acknowledge messages via the connection it has dialed.
+func (m *RemoteRef) sendWithAckMode(sender Ref, msg interface{}, mode AckMode) error {
+	if m.in != nil && mode == AckMode_AckNone && !m.in.isClosed() {
+		req, err := newSendNameRequest(sender, m.id.name, msg)
+		if err != nil {
+			return err
+		}
+		req.ToId, req.AckMode = m.id.id, AckMode_AckNone
+		return m.in.post(newSendNameMessage(req))
+	}
+	if m.node == nil {
+		return ErrRemoteConnNotFound
+	}
+	req, err := newSendNameRequest(sender, m.id.name, msg)
+	if err != nil {
+		return err
+	}
+	req.AckMode = mode
+	return sendName(m.node, req)
+}
+
+func newSendNameRequest(sender Ref, toName string, msg interface{}) (*SendName_Request, error) {
+	env, msg := unwrapEnvelope(msg)
 	sendData := &DataContentType{}
 	switch obj := msg.(type) {
 	case proto.Message:
 		sendAny, err := ptypes.MarshalAny(obj)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		sendData.Type = DataType_ProtoBuf
 		sendData.Content = &DataContentType_Proto{
@@ -115,30 +198,46 @@ func (m *RemoteRef) Send(sender Ref, msg interface{}) error {
 			Str: obj,
 		}
 	default:
-		return ErrRemoteRefAskType
+		return nil, ErrRemoteRefAskType
 	}
 
-	// send request
 	senderId, senderName := uint32(0), ""
 	if sender != nil {
 		senderId = sender.Id().id
 		senderName = sender.Id().name
 	}
-	w, err := m.node.send(&ConnMessage{
+	return &SendName_Request{
+		FromId:   senderId,
+		FromName: senderName,
+		ToName:   toName,
+		SendData: sendData,
+		Envelope: newConnEnvelope(env),
+	}, nil
+}
+
+func newSendNameMessage(sendReq *SendName_Request) *ConnMessage {
+	return &ConnMessage{
 		Type: ControlType_CSendName,
 		Content: &ConnMessage_SendName{
 			SendName: &SendName{
 				Data: &SendName_Req{
-					Req: &SendName_Request{
-						FromId:   senderId,
-						FromName: senderName,
-						ToName:   m.id.name,
-						SendData: sendData,
-					},
+					Req: sendReq,
 				},
 			},
 		},
-	})
+	}
+}
+
+func sendName(node *outNode, sendReq *SendName_Request) error {
+	req := newSendNameMessage(sendReq)
+	switch sendReq.AckMode {
+	case AckMode_AckNone:
+		return node.post(req)
+	case AckMode_AckBatch:
+		_, err := node.request(req, true)
+		return err
+	}
+	w, err := node.send(req)
 	if err != nil {
 		return err
 	}
@@ -147,6 +246,9 @@ func (m *RemoteRef) Send(sender Ref, msg interface{}) error {
 	select {
 	case respMsg, more := <-w.respCh:
 		{
+			if w.err != nil {
+				return w.err
+			}
 			if !more || respMsg == nil || respMsg.GetSendName() == nil || respMsg.GetSendName().GetResp() == nil {
 				return ErrRemoteResponse
 			}
@@ -159,173 +261,61 @@ func (m *RemoteRef) Send(sender Ref, msg interface{}) error {
 		}
 	case <-time.After(requestTimeout):
 		{
-			w.canceled = true
+			w.cancel()
 			return ErrRemoteTimeout
 		}
 	}
 }
 
-func interface2ContentType(data interface{}) (c *DataContentType, err error) {
-	c = &DataContentType{}
-	d := data
-	if reflect.ValueOf(data).Kind() == reflect.Ptr {
-		d = reflect.ValueOf(data).Elem().Interface()
+// Prototype of the answer, it is sent to the remote actor to tell the type of answer.
+func answerPrototype(answer reflect.Value) interface{} {
+	v := answer.Elem()
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return reflect.New(v.Type().Elem()).Interface()
 	}
-	switch dat := d.(type) {
-	case proto.Message:
-		sendAny, err := ptypes.MarshalAny(dat)
-		if err != nil {
-			return nil, err
-		}
-		c.Type = DataType_ProtoBuf
-		c.Content = &DataContentType_Proto{
-			Proto: sendAny,
-		}
-	case bool:
-		c.Type = DataType_Bool
-		c.Content = &DataContentType_B{
-			B: dat,
-		}
-	case []byte:
-		c.Type = DataType_Bytes
-		c.Content = &DataContentType_Bs{
-			Bs: dat,
-		}
-	case string:
-		c.Type = DataType_String
-		c.Content = &DataContentType_Str{
-			Str: dat,
-		}
-	case int:
-		c.Type = DataType_Int
-		c.Content = &DataContentType_I64{
-			I64: int64(dat),
-		}
-	case int8:
-		c.Type = DataType_Int8
-		c.Content = &DataContentType_I64{
-			I64: int64(dat),
-		}
-	case int16:
-		c.Type = DataType_Int16
-		c.Content = &DataContentType_I64{
-			I64: int64(dat),
-		}
-	case int32:
-		c.Type = DataType_Int32
-		c.Content = &DataContentType_I64{
-			I64: int64(dat),
-		}
-	case int64:
-		c.Type = DataType_Int64
-		c.Content = &DataContentType_I64{
-			I64: dat,
-		}
-	case uint:
-		c.Type = DataType_UInt
-		c.Content = &DataContentType_U64{
-			U64: uint64(dat),
-		}
-	case uint8:
-		c.Type = DataType_UInt8
-		c.Content = &DataContentType_U64{
-			U64: uint64(dat),
-		}
-	case uint16:
-		c.Type = DataType_UInt16
-		c.Content = &DataContentType_U64{
-			U64: uint64(dat),
-		}
-	case uint32:
-		c.Type = DataType_UInt32
-		c.Content = &DataContentType_U64{
-			U64: uint64(dat),
-		}
-	case uint64:
-		c.Type = DataType_UInt64
-		c.Content = &DataContentType_U64{
-			U64: dat,
-		}
-	case float32:
-		c.Type = DataType_Float32
-		c.Content = &DataContentType_F64{
-			F64: float64(dat),
-		}
-	case float64:
-		c.Type = DataType_Float64
-		c.Content = &DataContentType_F64{
-			F64: dat,
-		}
-	}
-	return c, errors.New("unsupported type")
+	return v.Interface()
 }
 
-func contentType2Interface(c *DataContentType, val reflect.Value) (err error) {
-	switch c.Type {
-	case DataType_ProtoBuf:
-		pb := c.GetProto()
-		err = ptypes.UnmarshalAny(c.GetProto(), pb)
-		if err != nil {
-			return err
-		}
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(pb).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().Set(reflect.ValueOf(pb))
-	case DataType_Bool:
-		b := c.GetB()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(b).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetBool(b)
-	case DataType_Bytes:
-		bs := c.GetBs()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(bs).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetBytes(bs)
-	case DataType_String:
-		s := c.GetStr()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(s).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetString(s)
-	case DataType_Int, DataType_Int8, DataType_Int16, DataType_Int32, DataType_Int64:
-		i := c.GetI64()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(i).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetInt(i)
-	case DataType_UInt, DataType_UInt8, DataType_UInt16, DataType_UInt32, DataType_UInt64:
-		u := c.GetU64()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(u).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetUint(u)
-	case DataType_Float32, DataType_Float64:
-		f := c.GetF64()
-		if !val.Elem().Type().AssignableTo(reflect.ValueOf(f).Type()) {
-			return ErrRemoteRefAnswerType
-		}
-		val.Elem().SetFloat(f)
-	default:
-		err = ErrRemoteRefAnswerType
+func setAnswer(answer reflect.Value, c *DataContentType) error {
+	v, err := decodeContent(c)
+	if err != nil {
+		return err
 	}
-	return
+	if !reflect.TypeOf(v).AssignableTo(answer.Elem().Type()) {
+		return ErrRemoteRefAnswerType
+	}
+	answer.Elem().Set(reflect.ValueOf(v))
+	return nil
 }
 
 // todo test answer type not pointer, answer non-struct type, struct contains slice and map
 func (m *RemoteRef) Ask(sender Ref, ask interface{}, answer interface{}) error {
+	return m.sys().interceptors.send(&OutboundContext{
+		Sender:  sender,
+		Target:  m,
+		Message: ask,
+		IsAsk:   true,
+		Answer:  answer,
+	}, func(ctx *OutboundContext) error {
+		return m.askMessage(ctx.Sender, ctx.Message, ctx.Answer)
+	})
+}
+
+func (m *RemoteRef) askMessage(sender Ref, ask interface{}, answer interface{}) error {
 	answerValue := reflect.ValueOf(answer)
 	if answerValue.Kind() != reflect.Ptr {
 		return ErrAnswerType
 	}
+	if m.node == nil {
+		return ErrRemoteConnNotFound
+	}
 
-	askData, err := interface2ContentType(ask)
+	env, ask := unwrapEnvelope(ask)
+	askData, err := encodeContent(ask)
 	if err != nil {
 		return err
 	}
-	answerData, err := interface2ContentType(answer)
+	answerData, err := encodeContent(answerPrototype(answerValue))
 	if err != nil {
 		return err
 	}
@@ -344,6 +334,7 @@ func (m *RemoteRef) Ask(sender Ref, ask interface{}, answer interface{}) error {
 						ToName:     m.id.name,
 						AskData:    askData,
 						AnswerData: answerData,
+						Envelope:   newConnEnvelope(env),
 					},
 				},
 			},
@@ -357,22 +348,24 @@ func (m *RemoteRef) Ask(sender Ref, ask interface{}, answer interface{}) error {
 	select {
 	case respMsg, more := <-w.respCh:
 		{
+			if w.err != nil {
+				return w.err
+			}
 			if !more || respMsg == nil || respMsg.GetAskName() == nil || respMsg.GetAskName().GetResp() == nil {
 				return ErrRemoteResponse
 			}
 			resp := respMsg.GetAskName().GetResp()
-			var respErr error
 			if resp.HasError {
-				respErr = errors.New(resp.ErrorMessage)
+				return errors.New(resp.ErrorMessage)
 			}
-			if resp.AnswerData != nil {
-				respErr = contentType2Interface(resp.AnswerData, answerValue)
+			if resp.AnswerData == nil {
+				return ErrRemoteResponse
 			}
-			return respErr
+			return setAnswer(answerValue, resp.AnswerData)
 		}
 	case <-time.After(requestTimeout):
 		{
-			w.canceled = true
+			w.cancel()
 			return ErrRemoteTimeout
 		}
 	}
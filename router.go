@@ -0,0 +1,260 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type RouterStrategy int
+
+const (
+	// Route messages to routees in turn.
+	RouteRoundRobin RouterStrategy = 0
+	// Route messages to a random routee.
+	RouteRandom RouterStrategy = 1
+	// Route messages to the local routee with the fewest queued messages, remote
+	// routees are chosen only if there is no running local routee.
+	RouteSmallestMailbox RouterStrategy = 2
+	// Route messages with the same key to the same routee, message should implement
+	// ConsistentHashable interface, or be a string.
+	RouteConsistentHash RouterStrategy = 3
+	// Route messages to all routees.
+	RouteBroadcast RouterStrategy = 4
+)
+
+const routerVirtualNodes = 100
+
+// Implement this interface to route a message with RouteConsistentHash strategy.
+type ConsistentHashable interface {
+	HashKey() string
+}
+
+//
+// Router
+//
+
+// Router is a Ref which fronts a pool of routee references, no matter LocalRef or
+// RemoteRef, messages sent to router are distributed to its running routees with
+// its strategy.
+type RouterRef struct {
+	strategy RouterStrategy
+	routees  []Ref
+	ring     []routerRingNode
+	lock     sync.RWMutex
+	counter  uint64
+}
+
+type routerRingNode struct {
+	hash   uint32
+	routee Ref
+}
+
+func NewRouter(strategy RouterStrategy, routees ...Ref) *RouterRef {
+	m := &RouterRef{
+		strategy: strategy,
+	}
+	for _, routee := range routees {
+		m.AddRoutee(routee)
+	}
+	return m
+}
+
+// Add a routee, it is ignored if it has been added.
+func (m *RouterRef) AddRoutee(routee Ref) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	id := routee.Id()
+	for _, r := range m.routees {
+		if r.Id() == id {
+			return
+		}
+	}
+	m.routees = append(m.routees, routee)
+	m.buildRing()
+}
+
+// Remove a routee with its id.
+func (m *RouterRef) RemoveRoutee(id Id) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i, r := range m.routees {
+		if r.Id() == id {
+			m.routees = append(m.routees[:i:i], m.routees[i+1:]...)
+			m.buildRing()
+			return
+		}
+	}
+}
+
+func (m *RouterRef) Routees() []Ref {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return append([]Ref{}, m.routees...)
+}
+
+func (m *RouterRef) buildRing() {
+	if m.strategy != RouteConsistentHash {
+		return
+	}
+	m.ring = make([]routerRingNode, 0, len(m.routees)*routerVirtualNodes)
+	for _, routee := range m.routees {
+		id := routee.Id()
+		for i := 0; i < routerVirtualNodes; i++ {
+			key := fmt.Sprintf("%d:%d:%s#%d", id.node, id.id, id.name, i)
+			m.ring = append(m.ring, routerRingNode{hash: routerHash(key), routee: routee})
+		}
+	}
+	sort.Slice(m.ring, func(i, j int) bool {
+		return m.ring[i].hash < m.ring[j].hash
+	})
+}
+
+func routerHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// Router has no actor id, it returns an empty id.
+func (m *RouterRef) Id() Id {
+	return Id{}
+}
+
+// Running if any routee is running.
+func (m *RouterRef) Status() Status {
+	for _, routee := range m.Routees() {
+		if routee.Status() == Running {
+			return Running
+		}
+	}
+	return Halt
+}
+
+// Send message to a routee chosen by strategy, or to all routees with RouteBroadcast
+// strategy, the first error is returned.
+func (m *RouterRef) Send(sender Ref, msg interface{}) error {
+	if m.strategy == RouteBroadcast {
+		routees := m.running()
+		if len(routees) == 0 {
+			return ErrRouterNoRoutee
+		}
+		var err error
+		for _, routee := range routees {
+			if e := routee.Send(sender, msg); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	}
+	routee, err := m.route(msg)
+	if err != nil {
+		return err
+	}
+	return routee.Send(sender, msg)
+}
+
+// Ask a routee chosen by strategy, RouteBroadcast strategy cannot ask.
+func (m *RouterRef) Ask(sender Ref, ask interface{}, answer interface{}) error {
+	if m.strategy == RouteBroadcast {
+		return ErrRouterBroadcastAsk
+	}
+	routee, err := m.route(ask)
+	if err != nil {
+		return err
+	}
+	return routee.Ask(sender, ask, answer)
+}
+
+// Shutdown all running routees, the first error is returned.
+func (m *RouterRef) Shutdown(sender Ref) error {
+	var err error
+	for _, routee := range m.running() {
+		if e := routee.Shutdown(sender); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (m *RouterRef) running() []Ref {
+	routees := m.Routees()
+	running := routees[:0]
+	for _, routee := range routees {
+		if routee.Status() == Running {
+			running = append(running, routee)
+		}
+	}
+	return running
+}
+
+func (m *RouterRef) route(msg interface{}) (Ref, error) {
+	if m.strategy == RouteConsistentHash {
+		return m.routeHash(msg)
+	}
+	routees := m.running()
+	if len(routees) == 0 {
+		return nil, ErrRouterNoRoutee
+	}
+	switch m.strategy {
+	case RouteRoundRobin:
+		n := atomic.AddUint64(&m.counter, 1)
+		return routees[(n-1)%uint64(len(routees))], nil
+	case RouteRandom:
+		return routees[rand.Intn(len(routees))], nil
+	case RouteSmallestMailbox:
+		var chosen Ref
+		size := -1
+		for _, routee := range routees {
+			lr, ok := routee.(*LocalRef)
+			if !ok {
+				continue
+			}
+			if s := lr.MailboxSize(); size < 0 || s < size {
+				chosen, size = lr, s
+				if s == 0 {
+					break
+				}
+			}
+		}
+		if chosen == nil {
+			chosen = routees[rand.Intn(len(routees))]
+		}
+		return chosen, nil
+	}
+	return nil, ErrArgument
+}
+
+// Find the first running routee clockwise from the hash of key on the ring.
+func (m *RouterRef) routeHash(msg interface{}) (Ref, error) {
+	var key string
+	_, msg = unwrapEnvelope(msg)
+	switch k := msg.(type) {
+	case ConsistentHashable:
+		key = k.HashKey()
+	case string:
+		key = k
+	default:
+		return nil, ErrRouterHashKey
+	}
+	hash := routerHash(key)
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	n := len(m.ring)
+	i := sort.Search(n, func(i int) bool {
+		return m.ring[i].hash >= hash
+	})
+	for j := 0; j < n; j++ {
+		routee := m.ring[(i+j)%n].routee
+		if routee.Status() == Running {
+			return routee, nil
+		}
+	}
+	return nil, ErrRouterNoRoutee
+}
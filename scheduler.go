@@ -0,0 +1,280 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Precision of scheduled messages.
+const SchedulerTick = 10 * time.Millisecond
+
+// Scheduled message, which can be canceled before it is sent.
+type Cancelable interface {
+	// Returns false if it has been canceled, or it has been sent and not periodic.
+	Cancel() bool
+	IsCanceled() bool
+}
+
+//
+// Scheduler API
+//
+
+// Send message to target after delay.
+func SendAfter(delay time.Duration, target Ref, msg interface{}) Cancelable {
+	return defaultSys.scheduler.schedule(nil, target, msg, delay, 0)
+}
+
+// Send message to target every interval, until it is canceled or target has halt.
+// A tick is skipped if the message of the last tick has not been sent yet, such as
+// the mailbox of target is full, so messages are sent in order.
+func SendEvery(interval time.Duration, target Ref, msg interface{}) Cancelable {
+	return defaultSys.scheduler.schedule(nil, target, msg, interval, interval)
+}
+
+// Send message to target after delay, on behalf of the actor. The message is
+// canceled if the actor has halt before it is sent.
+func (m *LocalRef) SendAfter(delay time.Duration, target Ref, msg interface{}) Cancelable {
+	return m.local.sys.scheduler.schedule(m, target, msg, delay, 0)
+}
+
+// Send message to target every interval on behalf of the actor, until it is
+// canceled, or either the actor or target has halt. A tick is skipped if the
+// message of the last tick has not been sent yet.
+func (m *LocalRef) SendEvery(interval time.Duration, target Ref, msg interface{}) Cancelable {
+	return m.local.sys.scheduler.schedule(m, target, msg, interval, interval)
+}
+
+//
+// Hierarchical Timing Wheel
+//
+
+// Level 0 has 256 slots of one tick, each upper level has 64 slots, a slot of
+// which covers all slots of its lower level. Timers are cascaded down to lower
+// levels as time goes by, and fired in level 0.
+const (
+	wheelLevel0Bits = 8
+	wheelLevelBits  = 6
+	wheelLevels     = 5
+	wheelMaxTicks   = 1<<(wheelLevel0Bits+wheelLevelBits*(wheelLevels-1)) - 1
+)
+
+type timingWheel struct {
+	lock    sync.Mutex
+	tick    time.Duration
+	startAt time.Time
+	current uint64
+	count   int
+	running bool
+	slots   [wheelLevels][]map[*timer]struct{}
+}
+
+type timer struct {
+	wheel    *timingWheel
+	owner    *LocalRef
+	target   Ref
+	msg      interface{}
+	expire   uint64
+	interval uint64
+	level    int
+	slot     int
+	canceled int32
+	sending  int32 // periodic timer is sending the message of last tick
+}
+
+func (m *timingWheel) init(tick time.Duration) {
+	m.tick = tick
+	m.startAt = time.Now()
+	for l := 0; l < wheelLevels; l++ {
+		n := 1 << wheelLevelBits
+		if l == 0 {
+			n = 1 << wheelLevel0Bits
+		}
+		m.slots[l] = make([]map[*timer]struct{}, n)
+		for i := range m.slots[l] {
+			m.slots[l][i] = map[*timer]struct{}{}
+		}
+	}
+}
+
+func (m *timingWheel) ticks(d time.Duration) uint64 {
+	t := uint64((d + m.tick - 1) / m.tick)
+	if t == 0 {
+		t = 1
+	}
+	if t > wheelMaxTicks {
+		t = wheelMaxTicks
+	}
+	return t
+}
+
+func (m *timingWheel) schedule(owner *LocalRef, target Ref, msg interface{}, delay, interval time.Duration) Cancelable {
+	t := &timer{
+		wheel:  m,
+		owner:  owner,
+		target: target,
+		msg:    msg,
+	}
+	if target == nil || (interval != 0 && interval < m.tick) {
+		t.canceled = 1
+		return t
+	}
+	if interval > 0 {
+		t.interval = m.ticks(interval)
+	}
+	if owner != nil && !owner.addHaltHook(t, func(*LocalRef) { t.Cancel() }) {
+		t.canceled = 1
+		return t
+	}
+	m.lock.Lock()
+	elapsed := time.Since(m.startAt)
+	if !m.running {
+		// no timer is running, skip the idle ticks
+		m.current = uint64(elapsed / m.tick)
+		m.running = true
+		go m.loop()
+	}
+	// expire at the first tick after delay, current tick might have passed partly
+	t.expire = uint64((elapsed + delay + m.tick - 1) / m.tick)
+	if t.expire <= m.current {
+		t.expire = m.current + 1
+	}
+	if t.expire-m.current > wheelMaxTicks {
+		t.expire = m.current + wheelMaxTicks
+	}
+	m.add(t)
+	m.lock.Unlock()
+	return t
+}
+
+// Put timer to the slot of its level, it should be called with lock.
+func (m *timingWheel) add(t *timer) {
+	// timer cascaded to current tick is fired right after cascading
+	if t.expire < m.current {
+		t.expire = m.current
+	}
+	delta := t.expire - m.current
+	level, shift := 0, uint(0)
+	limit := uint64(1) << wheelLevel0Bits
+	for level < wheelLevels-1 && delta >= limit {
+		level++
+		shift = wheelLevel0Bits + wheelLevelBits*uint(level-1)
+		limit <<= wheelLevelBits
+	}
+	t.level = level
+	t.slot = int(t.expire>>shift) & (len(m.slots[level]) - 1)
+	m.slots[level][t.slot][t] = struct{}{}
+	m.count++
+}
+
+func (m *timingWheel) remove(t *timer) {
+	if _, has := m.slots[t.level][t.slot][t]; has {
+		delete(m.slots[t.level][t.slot], t)
+		m.count--
+	}
+}
+
+func (m *timingWheel) loop() {
+	ticker := time.NewTicker(m.tick)
+	defer ticker.Stop()
+	for range ticker.C {
+		target := uint64(time.Since(m.startAt) / m.tick)
+		var fired []*timer
+		m.lock.Lock()
+		for m.current < target && m.count > 0 {
+			m.current++
+			m.cascade()
+			slot := m.slots[0][m.current&(1<<wheelLevel0Bits-1)]
+			for t := range slot {
+				delete(slot, t)
+				m.count--
+				fired = append(fired, t)
+				if t.interval > 0 {
+					t.expire += t.interval
+					m.add(t)
+				}
+			}
+		}
+		if m.count == 0 {
+			m.running = false
+			m.lock.Unlock()
+			m.fire(fired)
+			return
+		}
+		m.lock.Unlock()
+		m.fire(fired)
+	}
+}
+
+// Move timers of upper levels to lower levels, once all slots of lower levels
+// have been passed.
+func (m *timingWheel) cascade() {
+	shift := uint(wheelLevel0Bits)
+	for level := 1; level < wheelLevels; level++ {
+		if m.current&(1<<shift-1) != 0 {
+			return
+		}
+		slot := m.slots[level][int(m.current>>shift)&(len(m.slots[level])-1)]
+		for t := range slot {
+			delete(slot, t)
+			m.count--
+			m.add(t)
+		}
+		shift += wheelLevelBits
+	}
+}
+
+// Send messages of fired timers, sending might be blocked by a full mailbox, so
+// it should not block the wheel.
+func (m *timingWheel) fire(timers []*timer) {
+	for _, t := range timers {
+		if t.IsCanceled() {
+			continue
+		}
+		if t.interval == 0 {
+			atomic.StoreInt32(&t.canceled, 1)
+			if t.owner != nil {
+				t.owner.delHaltHook(t)
+			}
+			go t.send()
+			continue
+		}
+		if !atomic.CompareAndSwapInt32(&t.sending, 0, 1) {
+			continue
+		}
+		go func(t *timer) {
+			t.send()
+			atomic.StoreInt32(&t.sending, 0)
+		}(t)
+	}
+}
+
+func (t *timer) send() {
+	var sender Ref
+	if t.owner != nil {
+		sender = t.owner
+	}
+	if err := t.target.Send(sender, t.msg); err != nil && t.interval > 0 && t.target.Status() == Halt {
+		t.Cancel()
+	}
+}
+
+func (t *timer) Cancel() bool {
+	if !atomic.CompareAndSwapInt32(&t.canceled, 0, 1) {
+		return false
+	}
+	t.wheel.lock.Lock()
+	t.wheel.remove(t)
+	t.wheel.lock.Unlock()
+	if t.owner != nil {
+		t.owner.delHaltHook(t)
+	}
+	return true
+}
+
+func (t *timer) IsCanceled() bool {
+	return atomic.LoadInt32(&t.canceled) == 1
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import "sync/atomic"
+
+// Default capacity of stash, see WithStashCapacity.
+const DefaultStashCapacity = 1000
+
+// Spawn option, capacity of stash of the actor, Stash returns ErrStashFull once
+// the stash is full.
+func WithStashCapacity(n int) SpawnOption {
+	return func(o *spawnOptions) {
+		o.stashCapacity = n
+	}
+}
+
+// Stash the message being handled, it will be handled again after UnstashAll.
+// It should only be called inside HandleSend and HandleAsk, the asker of a stashed
+// ask keeps waiting until the ask is unstashed and answered.
+func (m *LocalRef) Stash() error {
+	if m.current == nil || m.stashed {
+		return ErrStashNoMessage
+	}
+	if len(m.stash) >= m.stashCapacity {
+		return ErrStashFull
+	}
+	m.stash = append(m.stash, m.current)
+	m.stashed = true
+	atomic.AddInt32(&m.stashSize, 1)
+	return nil
+}
+
+// Put all stashed messages back to the front of mailbox in original order, they
+// are handled before any other message in mailbox. Returns the number of unstashed
+// messages. It should only be called inside the actor.
+func (m *LocalRef) UnstashAll() int {
+	n := len(m.stash)
+	if n == 0 {
+		return 0
+	}
+	m.unstashed = append(m.stash, m.unstashed...)
+	m.stash = nil
+	atomic.StoreInt32(&m.stashSize, 0)
+	atomic.AddInt32(&m.recvPending, int32(n))
+	return n
+}
+
+// Number of stashed messages, it is safe to be called outside the actor.
+func (m *LocalRef) StashSize() int {
+	return int(atomic.LoadInt32(&m.stashSize))
+}
+
+// Fetch next message, unstashed messages go first.
+func (m *LocalRef) nextMessage() *message {
+	if len(m.unstashed) > 0 {
+		msg := m.unstashed[0]
+		m.unstashed[0] = nil
+		m.unstashed = m.unstashed[1:]
+		return msg
+	}
+	return <-m.recvCh
+}
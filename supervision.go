@@ -0,0 +1,164 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// Panic of an actor during handling a message, the asker of the message receives
+// it as the error of Ask.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("actor panic: %v", e.Value)
+}
+
+type PanicDirective int
+
+const (
+	// Keep the actor and its state, and handle the next message.
+	PanicResume PanicDirective = 0
+	// Replace the actor with a new one created by its spawn function and argument,
+	// Shutdown method of the panicked one is not called, and messages in mailbox
+	// are kept.
+	PanicRestart PanicDirective = 1
+	// Halt the actor, Shutdown method is not called. It is the default directive.
+	PanicStop PanicDirective = 2
+)
+
+// Decides how an actor continues after it panicked during handling a message.
+type PanicPolicy func(self *LocalRef, err *PanicError) PanicDirective
+
+// Set default panic policy of actors of the default system.
+func SetPanicPolicy(policy PanicPolicy) {
+	defaultSys.SetPanicPolicy(policy)
+}
+
+// Spawn option, panic policy of the actor instead of the default panic policy.
+func WithPanicPolicy(policy PanicPolicy) SpawnOption {
+	return func(o *spawnOptions) {
+		o.panicPolicy = policy
+	}
+}
+
+type supervisor struct {
+	lock   sync.RWMutex
+	policy PanicPolicy
+}
+
+func (m *supervisor) setPolicy(policy PanicPolicy) {
+	m.lock.Lock()
+	m.policy = policy
+	m.lock.Unlock()
+}
+
+func (m *supervisor) directive(r *LocalRef, err *PanicError) PanicDirective {
+	policy := r.panicPolicy
+	if policy == nil {
+		m.lock.RLock()
+		policy = m.policy
+		m.lock.RUnlock()
+	}
+	if policy == nil {
+		return PanicStop
+	}
+	return policy(r, err)
+}
+
+// Handle a message, panic is recovered and returned. Asker of the message receives
+// the panic, and a sent message is a dead letter.
+func (m *LocalRef) handleMessage(msg *message) (perr *PanicError) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		perr = &PanicError{Value: r, Stack: debug.Stack()}
+		log.Printf("actor.LocalRef %v recover from panic, %v\n%s", m.id, r, perr.Stack)
+		switch msg.msgType {
+		case msgTypeSend:
+			// a message which panics is not replayed
+			if msg.mailboxSeq > 0 {
+				m.mailbox.ack(msg.mailboxSeq)
+			}
+			m.local.sys.deadLetter(msg.sender, m.id, msg.msgContent, perr)
+		case msgTypeAsk:
+			m.answer(message{
+				sender:     msg.sender,
+				msgSession: msg.msgSession,
+				msgType:    msgTypeAnswer,
+				msgError:   perr,
+			})
+		}
+	}()
+	if m.dropExpired(msg) {
+		return nil
+	}
+	switch msg.msgType {
+	case msgTypeSend:
+		_, err := m.local.sys.interceptors.dispatch(m, msg, m.dispatchSend)
+		// rejected by interceptor
+		if err != nil && !m.stashed {
+			m.local.sys.deadLetter(msg.sender, m.id, msg.msgContent, err)
+		}
+		// stashed message is acknowledged after it has been unstashed and handled
+		if msg.mailboxSeq > 0 && !m.stashed {
+			m.mailbox.ack(msg.mailboxSeq)
+		}
+	case msgTypeAsk:
+		answer := message{
+			sender:     msg.sender,
+			msgSession: msg.msgSession,
+			msgType:    msgTypeAnswer,
+		}
+		answer.msgContent, answer.msgError = m.local.sys.interceptors.dispatch(m, msg, m.dispatchAsk)
+		// stashed ask is answered after it has been unstashed and handled
+		if !m.stashed {
+			m.answer(answer)
+		}
+	}
+	return nil
+}
+
+// Apply panic policy, returns false if the actor has been stopped.
+func (m *LocalRef) recoverPanic(perr *PanicError) bool {
+	switch m.local.sys.supervisor.directive(m, perr) {
+	case PanicResume:
+		return true
+	case PanicRestart:
+		err := m.restart()
+		if err == nil {
+			return true
+		}
+		log.Println("actor.LocalRef restart error,", m.id, err)
+	}
+	m.local.crashActor(m)
+	return false
+}
+
+// Replace the actor with a new one, it is started up in the actor goroutine.
+func (m *LocalRef) restart() error {
+	a := m.spawnFn()
+	m.actor = a
+	m.ask, _ = a.(Ask)
+	m.behaviors = nil
+	m.persistent = nil
+	if err := a.StartUp(m, m.spawnArg); err != nil {
+		return err
+	}
+	if p, ok := a.(PersistentActor); ok {
+		if err := m.local.sys.persistence.recoverActor(m, p); err != nil {
+			return err
+		}
+	}
+	a.Started()
+	return nil
+}
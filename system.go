@@ -9,6 +9,7 @@ import "regexp"
 func init() {
 	defaultSys = NewSystem()
 	Remote = &defaultSys.remote
+	Events = &defaultSys.events
 }
 
 // Go-actor provides a default system instance for use.
@@ -17,8 +18,16 @@ var defaultSys *system
 
 // It's the core of go-actor.
 type system struct {
-	locals localsManager
-	remote remoteManager
+	locals       localsManager
+	remote       remoteManager
+	persistence  persistenceManager
+	groups       groupsManager
+	events       eventStream
+	scheduler    timingWheel
+	passivation  passivationManager
+	interceptors interceptors
+	supervisor   supervisor
+	offload      offloadPool
 }
 
 // Developer can create system instance if needed, but not recommended.
@@ -31,14 +40,40 @@ func NewSystem() *system {
 func (m *system) init() {
 	m.locals.init(m)
 	m.remote.init(m)
+	m.persistence.init()
+	m.groups.init(m)
+	m.events.init(m)
+	m.scheduler.init(SchedulerTick)
+	m.passivation.init(m)
+	m.offload.init(DefaultGoWorkers)
 }
 
-func (m *system) Spawn(fn func() Actor, arg interface{}) (*LocalRef, error) {
-	return m.locals.spawnActor(fn, "", arg)
+func (m *system) Spawn(fn func() Actor, arg interface{}, opts ...SpawnOption) (*LocalRef, error) {
+	return m.locals.spawnActor(fn, "", arg, opts)
 }
 
-func (m *system) SpawnWithName(fn func() Actor, name string, arg interface{}) (*LocalRef, error) {
-	return m.locals.spawnActor(fn, name, arg)
+func (m *system) SpawnWithName(fn func() Actor, name string, arg interface{}, opts ...SpawnOption) (*LocalRef, error) {
+	return m.locals.spawnActor(fn, name, arg, opts)
+}
+
+func (m *system) AddInboundInterceptor(i InboundInterceptor) (remove func()) {
+	return m.interceptors.addInbound(i)
+}
+
+func (m *system) AddOutboundInterceptor(i OutboundInterceptor) (remove func()) {
+	return m.interceptors.addOutbound(i)
+}
+
+func (m *system) SetPanicPolicy(policy PanicPolicy) {
+	m.supervisor.setPolicy(policy)
+}
+
+func (m *system) SetGoWorkers(n int) {
+	m.offload.setWorkers(n)
+}
+
+func (m *system) SetJournal(journal Journal) {
+	m.persistence.setJournal(journal)
 }
 
 func (m *system) Register(ref Ref, name string) error {
@@ -53,8 +88,12 @@ func (m *system) ById(id uint32) *LocalRef {
 	return m.locals.getActorRef(id)
 }
 
+// Passivated actor is re-spawned if it is got by name.
 func (m *system) ByName(name string) *LocalRef {
-	return m.locals.getName(name)
+	if lr := m.locals.getName(name); lr != nil {
+		return lr
+	}
+	return m.passivation.activate(name)
 }
 
 func (m *system) SearchName(name string) map[string]*LocalRef {
@@ -72,7 +111,7 @@ func (m *system) SearchName(name string) map[string]*LocalRef {
 }
 
 func (m *system) Count() int {
-	return len(m.locals.actors)
+	return m.locals.count()
 }
 
 func (m *system) Remote() *remoteManager {
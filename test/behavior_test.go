@@ -0,0 +1,95 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+)
+
+// DOOR ACTOR, switches between closed and opened behaviors.
+
+type doorActor struct {
+	recorderActor
+	self *actor.LocalRef
+}
+
+func (m *doorActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+// closed
+func (m *doorActor) HandleSend(sender actor.Ref, message interface{}) {
+	if message == "open" {
+		m.self.Become(actor.Behavior{
+			Send: m.opened,
+			Ask: func(sender actor.Ref, ask interface{}) (interface{}, error) {
+				return "opened", nil
+			},
+		})
+	}
+}
+
+func (m *doorActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	return "closed", nil
+}
+
+func (m *doorActor) opened(sender actor.Ref, message interface{}) {
+	switch message {
+	case "close":
+		m.self.Unbecome()
+	case "lock":
+		// ask falls back to the actor
+		m.self.Become(actor.Behavior{Send: m.locked})
+	default:
+		m.recorderActor.HandleSend(sender, message)
+	}
+}
+
+func (m *doorActor) locked(sender actor.Ref, message interface{}) {
+	if message == "unlock" {
+		m.self.Unbecome()
+	}
+}
+
+func TestBecome(t *testing.T) {
+	a := &doorActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	state := func() string {
+		var s string
+		if err := ref.Ask(nil, "state", &s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	for _, step := range []struct {
+		send, want string
+	}{
+		{"", "closed"},
+		{"open", "opened"},
+		{"lock", "closed"},
+		{"unlock", "opened"},
+		{"close", "closed"},
+	} {
+		if step.send != "" {
+			if err := ref.Send(nil, step.send); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s := state(); s != step.want {
+			t.Fatalf("after %q state is %q, want %q", step.send, s, step.want)
+		}
+	}
+	if err := ref.Send(nil, "open"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Send(nil, "enter"); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 1); n != 1 {
+		t.Fatalf("received %d, want 1", n)
+	}
+}
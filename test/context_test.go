@@ -0,0 +1,92 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+// CONTEXT ACTOR, records headers of messages.
+
+type contextActor struct {
+	recorderActor
+	self *actor.LocalRef
+}
+
+func (m *contextActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+func (m *contextActor) HandleSend(sender actor.Ref, message interface{}) {
+	ctx := m.self.Context()
+	m.recorderActor.HandleSend(sender, message.(string)+" "+ctx.TraceId()+" "+ctx.TenantId()+" "+ctx.Header("custom"))
+}
+
+func (m *contextActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	return m.self.Context().TraceId(), nil
+}
+
+func TestMessageContext(t *testing.T) {
+	a := &contextActor{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return a }, "context_actor", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	env := (&actor.Envelope{Message: "local"}).
+		SetHeader(actor.HeaderTraceId, "t1").
+		SetHeader(actor.HeaderTenantId, "acme").
+		SetHeader("custom", "v")
+	if err := ref.Send(nil, env); err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Send(nil, "plain"); err != nil {
+		t.Fatal(err)
+	}
+	var traceId string
+	if err := ref.Ask(nil, (&actor.Envelope{Message: "ask"}).SetHeader(actor.HeaderTraceId, "t2"), &traceId); err != nil || traceId != "t2" {
+		t.Fatalf("answered %q %v, want t2", traceId, err)
+	}
+
+	// deadline
+	expired := (&actor.Envelope{Message: "late"}).SetDeadline(time.Now().Add(-time.Millisecond))
+	if err := ref.Ask(nil, expired, &traceId); err != actor.ErrDeadlineExceeded {
+		t.Fatal("ask after deadline should fail,", err)
+	}
+	if err := ref.Send(nil, expired); err != nil {
+		t.Fatal(err)
+	}
+	inTime := (&actor.Envelope{Message: "in time"}).SetDeadline(time.Now().Add(time.Minute))
+	if err := ref.Send(nil, inTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// remote frames
+	initRemote(t)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	remote, err := conn.ByName("context_actor")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	if err := remote.Send(nil, (&actor.Envelope{Message: "remote"}).SetHeader(actor.HeaderTraceId, "t3")); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 4); n != 4 {
+		t.Fatalf("received %d, want 4", n)
+	}
+	want := []string{"local t1 acme v", "plain   ", "in time   ", "remote t3  "}
+	got := a.received()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("received %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+func waitDeadLetter(t *testing.T, auditor *recorderActor, match func(dl *actor.DeadLetter) bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range auditor.received() {
+			if dl, ok := msg.(*actor.DeadLetter); ok && match(dl) {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("dead letter not found, received %v", auditor.received())
+}
+
+func TestDeadLetter(t *testing.T) {
+	initRemote(t)
+	auditors, refs := spawnRecorders(t, 1)
+	auditor := auditors[0]
+	defer refs[0].Shutdown(nil)
+	if err := actor.Events.Subscribe(refs[0], actor.DeadLetterTopic); err != nil {
+		t.Fatal(err)
+	}
+
+	// local actor has halt
+	_, halted := spawnRecorders(t, 1)
+	halted[0].Shutdown(nil)
+	for halted[0].Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	if err := halted[0].Send(refs[0], "lost"); err != actor.ErrActorNotRunning {
+		t.Fatal("send to halt actor should fail,", err)
+	}
+	waitDeadLetter(t, auditor, func(dl *actor.DeadLetter) bool {
+		return dl.Message == "lost" && dl.Target == halted[0].Id() &&
+			dl.Sender == refs[0] && dl.Reason == actor.ErrActorNotRunning
+	})
+
+	// remote actor name not found
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return &recorderActor{} }, "dead_letter_target", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := conn.ByName("dead_letter_target")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	lr.Shutdown(nil)
+	for lr.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	if err := ref.Send(nil, "remote lost"); err == nil {
+		t.Fatal("send to missing remote name should fail")
+	}
+	waitDeadLetter(t, auditor, func(dl *actor.DeadLetter) bool {
+		return dl.Message == "remote lost" && dl.Target.Name() == "dead_letter_target" &&
+			dl.Reason == actor.ErrRemoteActorNotFound
+	})
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+func TestPoolDispatcher(t *testing.T) {
+	const actors, messages = 1000, 10
+	pool := actor.NewPoolDispatcher(4, 5)
+	defer pool.Close()
+	recorders := make([]*recorderActor, actors)
+	refs := make([]*actor.LocalRef, actors)
+	for i := range refs {
+		recorders[i] = &recorderActor{}
+		a := recorders[i]
+		ref, err := actor.Spawn(func() actor.Actor { return a }, nil, actor.WithDispatcher(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs[i] = ref
+	}
+	for n := 0; n < messages; n++ {
+		for _, ref := range refs {
+			if err := ref.Send(nil, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if n := waitReceived(recorders, actors*messages); n != actors*messages {
+		t.Fatalf("received %d, want %d", n, actors*messages)
+	}
+	for _, r := range recorders {
+		for n, msg := range r.received() {
+			if msg != n {
+				t.Fatalf("received %v, want in order", r.received())
+			}
+		}
+	}
+	for _, ref := range refs {
+		if err := ref.Shutdown(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for _, ref := range refs {
+		for ref.Status() != actor.Halt {
+			if time.Now().After(deadline) {
+				t.Fatal("actor of pool dispatcher should halt")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// ask and panic
+	ref, err := actor.Spawn(func() actor.Actor { return &panicActor{} }, nil, actor.WithDispatcher(pool),
+		actor.WithPanicPolicy(func(self *actor.LocalRef, err *actor.PanicError) actor.PanicDirective {
+			return actor.PanicResume
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	askCount(t, ref)
+	askBoom(t, ref)
+	if n := askCount(t, ref); n != 2 {
+		t.Fatalf("count %d, want 2", n)
+	}
+}
+
+func TestPoolDispatcherStash(t *testing.T) {
+	const actors, messages = 100, 100
+	pool := actor.NewPoolDispatcher(4, 1)
+	defer pool.Close()
+	stashes := make([]*stashActor, actors)
+	recorders := make([]*recorderActor, actors)
+	refs := make([]*actor.LocalRef, actors)
+	for i := range refs {
+		stashes[i] = &stashActor{}
+		a := stashes[i]
+		recorders[i] = &a.recorderActor
+		ref, err := actor.Spawn(func() actor.Actor { return a }, nil, actor.WithDispatcher(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ref.Shutdown(nil)
+		refs[i] = ref
+	}
+	// unstashed messages are handled while more messages are put to mailbox
+	errCh := make(chan error, actors)
+	for _, ref := range refs {
+		go func(ref *actor.LocalRef) {
+			for n := 0; n < messages; n++ {
+				if n == messages/2 {
+					if err := ref.Send(nil, "ready"); err != nil {
+						errCh <- err
+						return
+					}
+				}
+				if err := ref.Send(nil, n); err != nil {
+					errCh <- err
+					return
+				}
+			}
+			errCh <- nil
+		}(ref)
+	}
+	for range refs {
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := waitReceived(recorders, actors*messages); n != actors*messages {
+		t.Fatalf("received %d, want %d", n, actors*messages)
+	}
+	for _, r := range recorders {
+		for n, msg := range r.received() {
+			if msg != n {
+				t.Fatalf("received %v, want in order", r.received())
+			}
+		}
+	}
+}
+
+// FAN-IN ACTOR, sends messages to target once it is told to.
+
+type fanInActor struct {
+	target *actor.LocalRef
+	count  int
+}
+
+func (m *fanInActor) Type() (name string, version int) {
+	return "fanIn", 1
+}
+
+func (m *fanInActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *fanInActor) Started() {
+}
+
+func (m *fanInActor) HandleSend(sender actor.Ref, message interface{}) {
+	for i := 0; i < m.count; i++ {
+		m.target.Send(nil, i)
+	}
+}
+
+func (m *fanInActor) Shutdown() {
+}
+
+func TestPoolDispatcherFanIn(t *testing.T) {
+	const senders, messages = 16, 10
+	pool := actor.NewPoolDispatcher(1, 5)
+	defer pool.Close()
+	recorder := &recorderActor{}
+	target, err := actor.Spawn(func() actor.Actor { return recorder }, nil, actor.WithDispatcher(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Shutdown(nil)
+	// senders share the only worker with target, they should not block on its mailbox
+	for i := 0; i < senders; i++ {
+		ref, err := actor.Spawn(func() actor.Actor { return &fanInActor{target: target, count: messages} }, nil, actor.WithDispatcher(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ref.Shutdown(nil)
+		if err := ref.Send(nil, "go"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := waitReceived([]*recorderActor{recorder}, senders*messages); n != senders*messages {
+		t.Fatalf("received %d, want %d", n, senders*messages)
+	}
+}
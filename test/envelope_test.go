@@ -0,0 +1,213 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"net"
+	"testing"
+	"time"
+)
+
+// PONG ACTOR, replies "pong" to any message.
+
+type pongActor struct {
+	recorderActor
+	self *actor.LocalRef
+}
+
+func (m *pongActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+func (m *pongActor) HandleSend(sender actor.Ref, message interface{}) {
+	if err := m.self.Reply("pong"); err != nil {
+		m.recorderActor.HandleSend(sender, err)
+	}
+}
+
+// ACKING PONG ACTOR, replies, and then sends to the sender waiting for the
+// acknowledgment, records the error of the acknowledged send.
+
+type ackingPongActor struct {
+	pongActor
+}
+
+func (m *ackingPongActor) HandleSend(sender actor.Ref, message interface{}) {
+	m.pongActor.HandleSend(sender, message)
+	if ref, ok := sender.(*actor.RemoteRef); ok {
+		m.recorderActor.HandleSend(sender, ref.SendWithAckMode(m.self, "acked", actor.SendAckEach))
+	}
+}
+
+// CORRELATING ACTOR, records messages with their correlation ids.
+
+type correlatingActor struct {
+	recorderActor
+	self *actor.LocalRef
+}
+
+func (m *correlatingActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+func (m *correlatingActor) HandleSend(sender actor.Ref, message interface{}) {
+	id := ""
+	if env := m.self.Envelope(); env != nil {
+		id = env.CorrelationId
+	}
+	m.recorderActor.HandleSend(sender, message.(string)+"/"+id)
+}
+
+func TestReply(t *testing.T) {
+	p := &pongActor{}
+	pong, err := actor.SpawnWithName(func() actor.Actor { return p }, "envelope_pong", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pong.Shutdown(nil)
+	requester := &correlatingActor{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return requester }, "envelope_requester", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	replyTo := &correlatingActor{}
+	replyToRef, err := actor.SpawnWithName(func() actor.Actor { return replyTo }, "envelope_reply_to", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replyToRef.Shutdown(nil)
+
+	// reply to sender, and reply-to reference
+	if err := pong.Send(ref, &actor.Envelope{CorrelationId: "1", Message: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pong.Send(ref, &actor.Envelope{CorrelationId: "2", ReplyTo: replyToRef, Message: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pong.Send(ref, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&requester.recorderActor, &replyTo.recorderActor}, 3); n != 3 {
+		t.Fatalf("received %d, want 3", n)
+	}
+	if got := requester.received(); got[0] != "pong/1" || got[1] != "pong/" {
+		t.Fatalf("requester received %v", got)
+	}
+	if got := replyTo.received(); got[0] != "pong/2" {
+		t.Fatalf("reply-to received %v", got)
+	}
+
+	// no reply target
+	if err := pong.Send(nil, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&p.recorderActor}, 1); n != 1 || p.received()[0] != actor.ErrNoReplyTarget {
+		t.Fatalf("received %v, want ErrNoReplyTarget", p.received())
+	}
+
+	// remote sender, reply via the connection to its node, reply-to actor should
+	// have name
+	initRemote(t)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	remotePong, err := conn.ByName("envelope_pong")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	if err := remotePong.Send(ref, &actor.Envelope{CorrelationId: "3", Message: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := remotePong.Send(ref, &actor.Envelope{CorrelationId: "4", ReplyTo: replyToRef, Message: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&requester.recorderActor, &replyTo.recorderActor}, 5); n != 5 {
+		t.Fatalf("received %d, want 5", n)
+	}
+	if got := requester.received(); got[2] != "pong/3" {
+		t.Fatalf("requester received %v", got)
+	}
+	if got := replyTo.received(); got[1] != "pong/4" {
+		t.Fatalf("reply-to received %v", got)
+	}
+}
+
+func TestReplyRemoteNotDialed(t *testing.T) {
+	const fromNodeId, fromId = 7, 42
+	initRemote(t)
+	p := &ackingPongActor{}
+	pong, err := actor.SpawnWithName(func() actor.Actor { return p }, "envelope_pong_undialed", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pong.Shutdown(nil)
+
+	// node 7 dials, the test node has not dialed node 7
+	conn, err := net.Dial("tcp", testNodeAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePacket(conn, &actor.ConnMessage{
+		SequenceId: 1,
+		Type:       actor.ControlType_CAuth,
+		Direction:  actor.Direction_Request,
+		Content: &actor.ConnMessage_Auth{Auth: &actor.Auth{
+			Data: &actor.Auth_Req{Req: &actor.Auth_Request{FromNodeId: fromNodeId, ToNodeId: testNodeId}},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := readPacket(conn); err != nil || !resp.GetAuth().GetResp().GetIsAuth() {
+		t.Fatal("auth failed,", resp, err)
+	}
+	if err := writePacket(conn, &actor.ConnMessage{
+		SequenceId: 2,
+		Type:       actor.ControlType_CSendName,
+		Direction:  actor.Direction_Request,
+		Content: &actor.ConnMessage_SendName{SendName: &actor.SendName{
+			Data: &actor.SendName_Req{Req: &actor.SendName_Request{
+				FromId:   fromId,
+				ToName:   "envelope_pong_undialed",
+				SendData: &actor.DataContentType{Type: actor.DataType_String, Content: &actor.DataContentType_Str{Str: "ping"}},
+				Envelope: &actor.ConnEnvelope{CorrelationId: "5"},
+			}},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// reply comes back via the same connection, addressed by id of sender
+	for {
+		packet, err := readPacket(conn)
+		if err != nil {
+			t.Fatal("no reply,", err, p.received())
+		}
+		if packet.Type != actor.ControlType_CSendName || packet.Direction != actor.Direction_Request {
+			continue
+		}
+		req := packet.GetSendName().GetReq()
+		if req.GetToId() != fromId || req.GetSendData().GetStr() != "pong" || req.GetEnvelope().GetCorrelationId() != "5" {
+			t.Fatalf("reply %v", req)
+		}
+		break
+	}
+
+	// only fire-and-forget replies are sent via the incoming connection
+	if n := waitReceived([]*recorderActor{&p.recorderActor}, 1); n != 1 {
+		t.Fatal("acknowledged send not attempted")
+	}
+	if received := p.received(); received[0] != actor.ErrRemoteConnNotFound {
+		t.Fatal("acknowledged send should not be downgraded,", received)
+	}
+}
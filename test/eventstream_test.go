@@ -0,0 +1,167 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type orderEvent struct {
+	id int
+}
+
+func TestEventStream(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 3)
+	defer refs[1].Shutdown(nil)
+	defer refs[2].Shutdown(nil)
+	if err := actor.Events.Subscribe(refs[0], "order.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(refs[0], "order.#"); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(refs[1], "order.created.#"); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.SubscribeType(refs[2], orderEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(refs[2], "order.*.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(refs[2], "order.#.bad#"); err != actor.ErrArgument {
+		t.Fatal("invalid pattern should fail,", err)
+	}
+
+	if n := actor.Events.Publish("order.created", "a"); n != 2 {
+		t.Fatalf("published to %d, want 2", n)
+	}
+	if n := actor.Events.Publish("order.created.eu", "b"); n != 3 {
+		t.Fatalf("published to %d, want 3", n)
+	}
+	if n := actor.Events.Publish("payment", orderEvent{id: 1}); n != 1 {
+		t.Fatalf("published to %d, want 1", n)
+	}
+	waitReceived(recorders, 6)
+	want := [][]interface{}{
+		{"a", "b"},
+		{"a", "b"},
+		{"b", orderEvent{id: 1}},
+	}
+	for i, r := range recorders {
+		if got := r.received(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("subscriber %d received %v, want %v", i, got, want[i])
+		}
+	}
+
+	// halted subscriber is unsubscribed
+	refs[0].Shutdown(nil)
+	for refs[0].Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	if n := actor.Events.Publish("order.paid", "c"); n != 0 {
+		t.Fatalf("published to %d, want 0", n)
+	}
+	actor.Events.UnsubscribeAll(refs[2])
+	if n := actor.Events.Publish("order.created.us", "d"); n != 1 {
+		t.Fatalf("published to %d, want 1", n)
+	}
+}
+
+func TestEventStreamPropagate(t *testing.T) {
+	initRemote(t)
+	recorders, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	if err := actor.Events.Subscribe(refs[0], "cluster.#"); err != nil {
+		t.Fatal(err)
+	}
+	// test node propagates to itself, so a message is received from local and remote
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	if err := actor.Events.Propagate(conn, "cluster.#"); err != nil {
+		t.Fatal(err)
+	}
+	defer actor.Events.StopPropagate(conn, "cluster.#")
+	actor.Events.Publish("cluster.member.up", "node-2")
+	actor.Events.Publish("local.only", "ignored")
+	if n := waitReceived(recorders, 2); n != 2 {
+		t.Fatalf("received %d, want 2", n)
+	}
+	for _, msg := range recorders[0].received() {
+		if msg != "node-2" {
+			t.Fatalf("received %v", msg)
+		}
+	}
+}
+
+func TestEventStreamBlockedSubscriber(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	blocking := &blockingActor{handled: make(chan int, 10)}
+	busy, err := actor.Spawn(func() actor.Actor { return blocking }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(busy, "blocked"); err != nil {
+		t.Fatal(err)
+	}
+	defer actor.Events.UnsubscribeAll(busy)
+	if err := actor.Events.Subscribe(refs[0], "blocked"); err != nil {
+		t.Fatal(err)
+	}
+
+	// busy subscriber blocks forever on message 2, publisher is not blocked
+	done := make(chan struct{})
+	go func() {
+		for i := 2; i < 12; i++ {
+			actor.Events.Publish("blocked", i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher is blocked by subscriber")
+	}
+	if n := waitReceived(recorders, 10); n != 10 {
+		t.Fatalf("received %d, want 10", n)
+	}
+}
+
+func TestEventStreamNamedSubscriberHalt(t *testing.T) {
+	recorder := &recorderActor{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "events_named", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.Subscribe(ref, "named.#"); err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Events.SubscribeType(ref, orderEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		actor.Events.Publish("named.event", i)
+	}
+	if n := waitReceived([]*recorderActor{recorder}, 10); n != 10 {
+		t.Fatalf("received %d, want 10", n)
+	}
+	ref.Shutdown(nil)
+	for ref.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	// halted subscriber is released by event stream, although its name has been cleared
+	if !waitCollected(ref) {
+		t.Fatal("halted named subscriber should be unsubscribed")
+	}
+	if n := actor.Events.Publish("named.event", "again"); n != 0 {
+		t.Fatalf("published to %d, want 0", n)
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"sync"
+	"testing"
+	"time"
+)
+
+// HANDSHAKE ACTOR, a finite state machine.
+
+const (
+	stateIdle       actor.FSMState = "idle"
+	stateConnecting actor.FSMState = "connecting"
+	stateConnected  actor.FSMState = "connected"
+)
+
+type connectEvent struct{}
+type ackEvent struct{}
+
+type handshakeActor struct {
+	actor.FSM
+	lock  sync.Mutex
+	trace []string
+}
+
+func (m *handshakeActor) Type() (name string, version int) {
+	return "handshake", 1
+}
+
+func (m *handshakeActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.On(stateIdle, connectEvent{}, func(sender actor.Ref, event interface{}) actor.FSMState {
+		return stateConnecting
+	})
+	m.On(stateConnecting, connectEvent{}, func(sender actor.Ref, event interface{}) actor.FSMState {
+		return stateConnecting
+	})
+	m.On(stateConnecting, ackEvent{}, func(sender actor.Ref, event interface{}) actor.FSMState {
+		return stateConnected
+	})
+	m.On(stateConnecting, actor.FSMStateTimeout{}, func(sender actor.Ref, event interface{}) actor.FSMState {
+		return stateIdle
+	})
+	m.SetStateTimeout(stateConnecting, 50*time.Millisecond)
+	m.OnEntry(stateConnected, func(from actor.FSMState) {
+		m.record("enter connected from " + string(from))
+	})
+	m.OnExit(stateConnecting, func(to actor.FSMState) {
+		m.record("exit connecting to " + string(to))
+	})
+	m.OnTransition(func(t *actor.FSMTransition) {
+		m.record(string(t.From) + "->" + string(t.To))
+	})
+	m.StartFSM(self, stateIdle)
+	return nil
+}
+
+func (m *handshakeActor) Started() {
+}
+
+func (m *handshakeActor) Shutdown() {
+}
+
+func (m *handshakeActor) record(s string) {
+	m.lock.Lock()
+	m.trace = append(m.trace, s)
+	m.lock.Unlock()
+}
+
+func (m *handshakeActor) waitTrace(t *testing.T, want ...string) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		m.lock.Lock()
+		trace := append([]string{}, m.trace...)
+		m.lock.Unlock()
+		if len(trace) >= len(want) {
+			for i := range want {
+				if trace[i] != want[i] {
+					t.Fatalf("trace %v, want %v", trace, want)
+				}
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("trace %v, want %v", trace, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFSM(t *testing.T) {
+	auditors, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	if err := actor.Events.Subscribe(refs[0], actor.DeadLetterTopic); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &handshakeActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+
+	// connecting state times out
+	if err := ref.Send(nil, connectEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	a.waitTrace(t, "->idle", "idle->connecting", "exit connecting to idle", "connecting->idle")
+
+	// handshake completes before timeout
+	a.lock.Lock()
+	a.trace = nil
+	a.lock.Unlock()
+	for _, event := range []interface{}{connectEvent{}, connectEvent{}, ackEvent{}} {
+		if err := ref.Send(nil, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.waitTrace(t, "idle->connecting", "exit connecting to connected",
+		"enter connected from connecting", "connecting->connected")
+	time.Sleep(100 * time.Millisecond)
+	a.waitTrace(t, "idle->connecting", "exit connecting to connected",
+		"enter connected from connecting", "connecting->connected")
+	a.lock.Lock()
+	if len(a.trace) != 4 {
+		t.Fatalf("trace %v, stale timeout should be ignored", a.trace)
+	}
+	a.lock.Unlock()
+
+	// unhandled event is a dead letter
+	if err := ref.Send(refs[0], connectEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	waitDeadLetter(t, auditors[0], func(dl *actor.DeadLetter) bool {
+		return dl.Target == ref.Id() && dl.Reason == actor.ErrFSMEventUnhandled
+	})
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ECHO ACTOR, answers the ask with its tag.
+
+type echoActor struct {
+	recorderActor
+	tag string
+}
+
+func (m *echoActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.tag = arg.(string)
+	return nil
+}
+
+func (m *echoActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	return m.tag + ":" + ask.(string), nil
+}
+
+func TestGroup(t *testing.T) {
+	initRemote(t)
+	a, err := actor.Spawn(func() actor.Actor { return &echoActor{} }, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := actor.SpawnWithName(func() actor.Actor { return &echoActor{} }, "group_b", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Shutdown(nil)
+	remoteActor := &echoActor{}
+	remote, err := actor.SpawnWithName(func() actor.Actor { return remoteActor }, "group_remote", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	remoteRef, err := conn.ByName("group_remote")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+
+	g := actor.Group("test_group")
+	defer actor.DelGroup("test_group")
+	if actor.Group("test_group") != g {
+		t.Fatal("named group should be the same")
+	}
+	if err := g.AddRef(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddName("group_b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddName("group_missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddRef(remoteRef); err != nil {
+		t.Fatal(err)
+	}
+
+	answers, errs := g.Ask(nil, "hi", new(string))
+	if len(answers) != 3 || answers[a.Id()] != "a:hi" || answers[b.Id()] != "b:hi" || answers[remoteRef.Id()] != "r:hi" {
+		t.Fatalf("answers %v, errors %v", answers, errs)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("errors %v, want missing name only", errs)
+	}
+	for id, err := range errs {
+		if id.Name() != "group_missing" || err != actor.ErrActorNotRunning {
+			t.Fatalf("errors %v, want missing name only", errs)
+		}
+	}
+
+	// halted member is removed
+	a.Shutdown(nil)
+	for a.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	g.DelName("group_missing")
+	if errs := g.Send(nil, "hello"); len(errs) != 0 {
+		t.Fatalf("send errors %v", errs)
+	}
+	if n := waitReceived([]*recorderActor{&remoteActor.recorderActor}, 1); n != 1 {
+		t.Fatalf("remote member received %d, want 1", n)
+	}
+	if members, _ := g.Members(); len(members) != 2 {
+		t.Fatalf("members %v, want 2", members)
+	}
+
+	// added by both reference and name, it is a member once
+	if err := g.AddRef(b); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := g.Members(); len(members) != 2 {
+		t.Fatalf("members %v, want 2", members)
+	}
+
+	g.DelRef(b)
+	g.DelRef(remoteRef)
+	g.DelName("group_b")
+	if members, _ := g.Members(); len(members) != 0 {
+		t.Fatalf("members %v, want none", members)
+	}
+}
+
+// Wait until ref has been garbage collected, returns false if it is still referenced.
+func waitCollected(ref *actor.LocalRef) bool {
+	var collected int32
+	runtime.SetFinalizer(ref, func(*actor.LocalRef) { atomic.StoreInt32(&collected, 1) })
+	ref = nil
+	for i := 0; i < 50 && atomic.LoadInt32(&collected) == 0; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	return atomic.LoadInt32(&collected) == 1
+}
+
+func TestGroupNamedMemberHalt(t *testing.T) {
+	g := actor.Group("test_group_named")
+	defer actor.DelGroup("test_group_named")
+	ref, err := actor.SpawnWithName(func() actor.Actor { return &echoActor{} }, "group_named", "n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddRef(ref); err != nil {
+		t.Fatal(err)
+	}
+	ref.Shutdown(nil)
+	for ref.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	// halted member is released by group, although its name has been cleared
+	if !waitCollected(ref) {
+		t.Fatal("halted named member should be removed from group")
+	}
+	if members, _ := g.Members(); len(members) != 0 {
+		t.Fatalf("members %v, want none", members)
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"fmt"
+	"github.com/hwangtou/go-actor"
+	"testing"
+)
+
+// DISPATCH ACTOR, handles messages with registered handlers.
+
+type depositMsg struct {
+	amount int
+}
+
+type balanceAsk struct{}
+
+type dispatchActor struct {
+	actor.Handlers
+	recorderActor
+	balance int
+}
+
+func (m *dispatchActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.InitHandlers(self)
+	actor.On(&m.Handlers, func(sender actor.Ref, msg depositMsg) {
+		m.balance += msg.amount
+	})
+	actor.On(&m.Handlers, func(sender actor.Ref, msg fmt.Stringer) {
+		m.recorderActor.HandleSend(sender, msg.String())
+	})
+	actor.OnAsk(&m.Handlers, func(sender actor.Ref, ask balanceAsk) (int, error) {
+		return m.balance, nil
+	})
+	return nil
+}
+
+func (m *dispatchActor) HandleSend(sender actor.Ref, message interface{}) {
+	m.Handlers.HandleSend(sender, message)
+}
+
+type stringerMsg struct{}
+
+func (stringerMsg) String() string {
+	return "stringer"
+}
+
+func TestHandlers(t *testing.T) {
+	auditors, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	if err := actor.Events.Subscribe(refs[0], actor.DeadLetterTopic); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &dispatchActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	for _, msg := range []interface{}{depositMsg{3}, depositMsg{4}, stringerMsg{}} {
+		if err := ref.Send(nil, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var balance int
+	if err := ref.Ask(nil, balanceAsk{}, &balance); err != nil || balance != 7 {
+		t.Fatalf("balance %d %v, want 7", balance, err)
+	}
+	if got := a.received(); len(got) != 1 || got[0] != "stringer" {
+		t.Fatalf("received %v, want stringer", got)
+	}
+
+	// unhandled
+	if err := ref.Ask(nil, "balance", &balance); err != actor.ErrMessageUnhandled {
+		t.Fatal("unhandled ask should fail,", err)
+	}
+	if err := ref.Send(refs[0], 42); err != nil {
+		t.Fatal(err)
+	}
+	waitDeadLetter(t, auditors[0], func(dl *actor.DeadLetter) bool {
+		return dl.Message == 42 && dl.Target == ref.Id() && dl.Reason == actor.ErrMessageUnhandled
+	})
+
+	// fallback
+	a.SetFallback(func(sender actor.Ref, msg interface{}) {
+		a.recorderActor.HandleSend(sender, msg)
+	})
+	if err := ref.Send(nil, 43); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 2); n != 2 {
+		t.Fatalf("received %d, want 2", n)
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"errors"
+	"github.com/hwangtou/go-actor"
+	"sync"
+	"testing"
+)
+
+// FRAGILE ACTOR, panics on "boom".
+
+type fragileActor struct {
+	recorderActor
+}
+
+func (m *fragileActor) HandleSend(sender actor.Ref, message interface{}) {
+	if message == "boom" {
+		panic("boom")
+	}
+	m.recorderActor.HandleSend(sender, message)
+}
+
+func (m *fragileActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	return ask, nil
+}
+
+type interceptedMsg string
+
+func TestInterceptors(t *testing.T) {
+	var lock sync.Mutex
+	var trace []string
+	record := func(s string) {
+		lock.Lock()
+		trace = append(trace, s)
+		lock.Unlock()
+	}
+	errForbidden := errors.New("forbidden")
+
+	removeInbound := actor.AddInboundInterceptor(func(ctx *actor.InboundContext, next actor.InboundHandler) (interface{}, error) {
+		if msg, ok := ctx.Message.(interceptedMsg); ok {
+			record("system " + string(msg))
+			ctx.Message = string(msg)
+		}
+		return next(ctx)
+	})
+	defer removeInbound()
+	removeOutbound := actor.AddOutboundInterceptor(func(ctx *actor.OutboundContext, next actor.OutboundHandler) error {
+		if msg, ok := ctx.Message.(interceptedMsg); ok {
+			record("outbound " + string(msg))
+		}
+		return next(ctx)
+	})
+	defer removeOutbound()
+	auth := func(ctx *actor.InboundContext, next actor.InboundHandler) (interface{}, error) {
+		if ctx.Message == "forbidden" {
+			return nil, errForbidden
+		}
+		record("actor " + ctx.Message.(string))
+		return next(ctx)
+	}
+	capture := func(ctx *actor.InboundContext, next actor.InboundHandler) (answer interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.New("captured")
+			}
+		}()
+		return next(ctx)
+	}
+
+	a := &fragileActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil, actor.WithInboundInterceptors(capture, auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+
+	for _, msg := range []interface{}{interceptedMsg("hello"), "forbidden", "boom", "world"} {
+		if err := ref.Send(nil, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var answer string
+	if err := ref.Ask(nil, "forbidden", &answer); err != errForbidden {
+		t.Fatal("ask should be rejected,", err)
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 2); n != 2 {
+		t.Fatalf("received %d, want 2", n)
+	}
+	got := a.received()
+	if got[0] != "hello" || got[1] != "world" {
+		t.Fatalf("received %v", got)
+	}
+	lock.Lock()
+	want := []string{"outbound hello", "system hello", "actor hello", "actor boom", "actor world"}
+	if len(trace) != len(want) {
+		t.Fatalf("trace %v, want %v", trace, want)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("trace %v, want %v", trace, want)
+		}
+	}
+	lock.Unlock()
+
+	// outbound interceptors of sender
+	sender, err := actor.Spawn(func() actor.Actor { return &recorderActor{} }, nil,
+		actor.WithOutboundInterceptors(func(ctx *actor.OutboundContext, next actor.OutboundHandler) error {
+			if ctx.Message == "forbidden" {
+				return errForbidden
+			}
+			ctx.Message = ctx.Message.(string) + "!"
+			return next(ctx)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Shutdown(nil)
+	if err := ref.Send(sender, "forbidden"); err != errForbidden {
+		t.Fatal("send should be rejected,", err)
+	}
+	if err := ref.Send(sender, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Ask(sender, "hey", &answer); err != nil || answer != "hey!" {
+		t.Fatalf("answered %q %v, want hey!", answer, err)
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 3); n != 3 || a.received()[2] != "hi!" {
+		t.Fatalf("received %v", a.received())
+	}
+}
+
+func TestRemoveInterceptor(t *testing.T) {
+	errRejected := errors.New("rejected")
+	remove := actor.AddOutboundInterceptor(func(ctx *actor.OutboundContext, next actor.OutboundHandler) error {
+		return errRejected
+	})
+	a := &recorderActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	if err := ref.Send(nil, "hello"); err != errRejected {
+		remove()
+		t.Fatal("send should be rejected,", err)
+	}
+	remove()
+	remove()
+	if err := ref.Send(nil, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived([]*recorderActor{a}, 1); n != 1 {
+		t.Fatalf("received %d, want 1", n)
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	ossignal "os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const (
+	durableFailEnv  = "GO_ACTOR_DURABLE_FAIL_DIR"
+	durableFailName = "durable_fail"
+)
+
+// Runs in a child process, appending a message fails since the file size limit
+// has been exceeded, and then the process crashes with unacknowledged messages.
+func durableFail(dir string) {
+	a := &blockingActor{handled: make(chan int, 1)}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return a }, durableFailName, nil, actor.WithDurableMailbox(dir))
+	if err != nil {
+		os.Exit(2)
+	}
+	ref.Send(nil, 1)
+	<-a.handled
+	ref.Send(nil, 2)
+
+	info, err := os.Stat(filepath.Join(dir, url.PathEscape(durableFailName)+".mailbox"))
+	if err != nil {
+		os.Exit(2)
+	}
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_FSIZE, &limit); err != nil {
+		os.Exit(2)
+	}
+	ossignal.Ignore(syscall.SIGXFSZ)
+	// only a part of the next record can be written
+	small := limit
+	small.Cur = uint64(info.Size()) + 4
+	if err := syscall.Setrlimit(syscall.RLIMIT_FSIZE, &small); err != nil {
+		os.Exit(2)
+	}
+	if err := ref.Send(nil, 3); err == nil {
+		os.Exit(4)
+	}
+	if err := syscall.Setrlimit(syscall.RLIMIT_FSIZE, &limit); err != nil {
+		os.Exit(2)
+	}
+	if err := ref.Send(nil, 4); err != nil {
+		os.Exit(5)
+	}
+	os.Exit(3)
+}
+
+func TestDurableMailboxAppendFail(t *testing.T) {
+	if dir := os.Getenv(durableFailEnv); dir != "" {
+		durableFail(dir)
+		return
+	}
+	dir, err := ioutil.TempDir("", "go-actor-mailbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDurableMailboxAppendFail$")
+	cmd.Env = append(os.Environ(), durableFailEnv+"="+dir)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("child process should crash")
+	} else if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 3 {
+		t.Fatal("child process error,", err)
+	}
+
+	// the partial record of the failed message has been removed
+	recorder := &recorderActor{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return recorder }, durableFailName, nil, actor.WithDurableMailbox(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	want := []interface{}{2, 4}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.received()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := recorder.received(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	durableEnv  = "GO_ACTOR_DURABLE_CRASH_DIR"
+	durableName = "durable_orders"
+)
+
+// BLOCKING ACTOR, blocks forever once it receives message 2.
+
+type blockingActor struct {
+	handled chan int
+}
+
+func (m *blockingActor) Type() (name string, version int) {
+	return "blocking", 1
+}
+
+func (m *blockingActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *blockingActor) Started() {
+}
+
+func (m *blockingActor) HandleSend(sender actor.Ref, message interface{}) {
+	if message.(int) == 2 {
+		select {}
+	}
+	m.handled <- message.(int)
+}
+
+func (m *blockingActor) Shutdown() {
+}
+
+// Runs in a child process, which crashes with unacknowledged messages.
+func durableCrash(dir string) {
+	a := &blockingActor{handled: make(chan int, 1)}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return a }, durableName, nil, actor.WithDurableMailbox(dir))
+	if err != nil {
+		os.Exit(2)
+	}
+	ref.Send(nil, 1)
+	<-a.handled
+	ref.Send(nil, 2)
+	ref.Send(nil, 3)
+	os.Exit(3)
+}
+
+func TestDurableMailbox(t *testing.T) {
+	if dir := os.Getenv(durableEnv); dir != "" {
+		durableCrash(dir)
+		return
+	}
+	dir, err := ioutil.TempDir("", "go-actor-mailbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDurableMailbox$")
+	cmd.Env = append(os.Environ(), durableEnv+"="+dir)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("child process should crash")
+	} else if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 3 {
+		t.Fatal("child process error,", err)
+	}
+
+	if _, err := actor.Spawn(func() actor.Actor { return &recorderActor{} }, nil, actor.WithDurableMailbox(dir)); err != actor.ErrDurableMailboxName {
+		t.Fatal("durable mailbox without name should fail,", err)
+	}
+
+	recorder := &recorderActor{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return recorder }, durableName, nil, actor.WithDurableMailbox(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Send(nil, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{2, 3, 4}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.received()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := recorder.received(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed %v, want %v", got, want)
+	}
+	if err := ref.Send(nil, func() {}); err != actor.ErrContentType {
+		t.Fatal("unserializable message should fail,", err)
+	}
+	ref.Shutdown(nil)
+	for ref.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+
+	// all messages have been acknowledged
+	recorder = &recorderActor{}
+	ref, err = actor.SpawnWithName(func() actor.Actor { return recorder }, durableName, nil, actor.WithDurableMailbox(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	time.Sleep(10 * time.Millisecond)
+	if got := recorder.received(); len(got) != 0 {
+		t.Fatalf("replayed %v, want nothing", got)
+	}
+}
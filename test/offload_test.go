@@ -0,0 +1,118 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// OFFLOAD ACTOR, runs blocking work in background.
+
+type offloadActor struct {
+	recorderActor
+	self    *actor.LocalRef
+	running int32
+	peak    int32
+}
+
+func (m *offloadActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+func (m *offloadActor) HandleSend(sender actor.Ref, message interface{}) {
+	switch msg := message.(type) {
+	case string:
+		m.self.Go(func() (interface{}, error) {
+			if msg == "boom" {
+				panic(msg)
+			}
+			n := atomic.AddInt32(&m.running, 1)
+			for p := atomic.LoadInt32(&m.peak); n > p && !atomic.CompareAndSwapInt32(&m.peak, p, n); p = atomic.LoadInt32(&m.peak) {
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&m.running, -1)
+			return msg + " done", nil
+		})
+	case *actor.GoResult:
+		if msg.Err != nil {
+			m.recorderActor.HandleSend(sender, msg.Err)
+			return
+		}
+		m.recorderActor.HandleSend(sender, msg.Value)
+	}
+}
+
+func TestGo(t *testing.T) {
+	actor.SetGoWorkers(2)
+	defer actor.SetGoWorkers(actor.DefaultGoWorkers)
+	a := &offloadActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	for _, msg := range []string{"a", "b", "c", "d", "e", "boom"} {
+		if err := ref.Send(nil, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 6); n != 6 {
+		t.Fatalf("received %d, want 6", n)
+	}
+	done, panicked := 0, 0
+	for _, msg := range a.received() {
+		switch msg.(type) {
+		case string:
+			done++
+		case *actor.PanicError:
+			panicked++
+		}
+	}
+	if done != 5 || panicked != 1 {
+		t.Fatalf("received %v", a.received())
+	}
+	if p := atomic.LoadInt32(&a.peak); p > 2 {
+		t.Fatalf("%d works ran concurrently, want at most 2", p)
+	}
+}
+
+func TestGoQueued(t *testing.T) {
+	const works = 200
+	actor.SetGoWorkers(2)
+	defer actor.SetGoWorkers(actor.DefaultGoWorkers)
+	a := &offloadActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	gate := make(chan struct{})
+	var running, peak int32
+	before := runtime.NumGoroutine()
+	for i := 0; i < works; i++ {
+		ref.Go(func() (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			<-gate
+			atomic.AddInt32(&running, -1)
+			return "done", nil
+		})
+	}
+	// queued work does not hold goroutines
+	if n := runtime.NumGoroutine() - before; n > 10 {
+		t.Fatalf("%d goroutines for %d queued works", n, works)
+	}
+	// resizing while work is running keeps the bound
+	actor.SetGoWorkers(4)
+	close(gate)
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, works); n != works {
+		t.Fatalf("received %d, want %d", n, works)
+	}
+	if p := atomic.LoadInt32(&peak); p > 4 {
+		t.Fatalf("%d works ran concurrently, want at most 4", p)
+	}
+}
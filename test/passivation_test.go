@@ -0,0 +1,144 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// IDLE ACTOR, counts its incarnations.
+
+type idleActor struct {
+	recorderActor
+}
+
+type idleArg struct {
+	startups       int32
+	receiveTimeout time.Duration
+}
+
+func (m *idleActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	a := arg.(*idleArg)
+	atomic.AddInt32(&a.startups, 1)
+	self.SetReceiveTimeout(a.receiveTimeout)
+	return nil
+}
+
+// FAREWELL ACTOR, sends a message to itself while its first incarnation is being
+// passivated.
+
+type farewellActor struct {
+	*recorderActor
+	self *actor.LocalRef
+	arg  *idleArg
+}
+
+func (m *farewellActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	atomic.AddInt32(&m.arg.startups, 1)
+	return nil
+}
+
+func (m *farewellActor) Shutdown() {
+	if atomic.LoadInt32(&m.arg.startups) == 1 {
+		m.self.Send(nil, "farewell")
+	}
+}
+
+func TestReceiveTimeout(t *testing.T) {
+	a := &idleActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, &idleArg{receiveTimeout: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 2); n < 2 {
+		t.Fatalf("received %d, want repeated receive timeout", n)
+	}
+	for _, msg := range a.received() {
+		if _, ok := msg.(actor.ReceiveTimeout); !ok {
+			t.Fatalf("received %v, want receive timeout", msg)
+		}
+	}
+}
+
+func TestPassivation(t *testing.T) {
+	const name = "passivated_user"
+	arg := &idleArg{}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return &idleActor{} }, name, arg, actor.WithPassivation(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Send(nil, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for ref.Status() != actor.Halt {
+		if time.Now().After(deadline) {
+			t.Fatal("idle actor should be passivated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// message to the previous incarnation re-spawns the actor, it might be queued
+	// until the previous incarnation has halt
+	if err := ref.Send(nil, "again"); err != nil {
+		t.Fatal(err)
+	}
+	for atomic.LoadInt32(&arg.startups) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("started up %d times, want 2", atomic.LoadInt32(&arg.startups))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	active := actor.ByName(name)
+	if active == nil || active == ref || active.Status() != actor.Running {
+		t.Fatal("passivated actor should be re-spawned")
+	}
+	defer active.Shutdown(nil)
+	if n := atomic.LoadInt32(&arg.startups); n != 2 {
+		t.Fatalf("started up %d times, want 2", n)
+	}
+
+	if _, err := actor.Spawn(func() actor.Actor { return &idleActor{} }, arg, actor.WithPassivation(time.Second)); err != actor.ErrPassivationName {
+		t.Fatal("passivation without name should fail,", err)
+	}
+}
+
+func TestPassivationReceiveTimeout(t *testing.T) {
+	const name = "passivated_receive_timeout"
+	arg := &idleArg{receiveTimeout: 20 * time.Millisecond}
+	ref, err := actor.SpawnWithName(func() actor.Actor { return &idleActor{} }, name, arg, actor.WithPassivation(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// receive timeouts do not keep the actor active
+	deadline := time.Now().Add(time.Second)
+	for ref.Status() != actor.Halt {
+		if time.Now().After(deadline) {
+			ref.Shutdown(nil)
+			t.Fatal("actor with receive timeout should be passivated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPassivationSendToSelf(t *testing.T) {
+	const name = "passivated_farewell"
+	recorder, arg := &recorderActor{}, &idleArg{}
+	fn := func() actor.Actor { return &farewellActor{recorderActor: recorder, arg: arg} }
+	if _, err := actor.SpawnWithName(fn, name, nil, actor.WithPassivation(30*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	// message sent during passivating is delivered to the new incarnation
+	if n := waitReceived([]*recorderActor{recorder}, 1); n != 1 {
+		t.Fatalf("received %d, want 1", n)
+	}
+	if n := atomic.LoadInt32(&arg.startups); n != 2 {
+		t.Fatalf("started up %d times, want 2", n)
+	}
+	if active := actor.ByName(name); active != nil {
+		active.Shutdown(nil)
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ACCOUNT ACTOR
+
+type accountActor struct {
+	self    *actor.LocalRef
+	id      string
+	balance int64
+}
+
+func (m *accountActor) Type() (name string, version int) {
+	return "account", 1
+}
+
+func (m *accountActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	m.id = arg.(string)
+	return nil
+}
+
+func (m *accountActor) Started() {
+}
+
+func (m *accountActor) HandleSend(sender actor.Ref, message interface{}) {
+}
+
+func (m *accountActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	switch a := ask.(type) {
+	case int64:
+		if err := m.self.Persist(a); err != nil {
+			return nil, err
+		}
+	case string:
+		if a == "snapshot" {
+			if err := m.self.SaveSnapshot(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.balance, nil
+}
+
+func (m *accountActor) Shutdown() {
+}
+
+func (m *accountActor) PersistenceId() string {
+	return "account/" + m.id
+}
+
+func (m *accountActor) ApplyEvent(event interface{}) {
+	m.balance += event.(int64)
+}
+
+func (m *accountActor) Snapshot() (interface{}, error) {
+	return m.balance, nil
+}
+
+func (m *accountActor) Restore(snapshot interface{}) error {
+	m.balance = snapshot.(int64)
+	return nil
+}
+
+func spawnAccount(t *testing.T, id string) *actor.LocalRef {
+	ref, err := actor.Spawn(func() actor.Actor { return &accountActor{} }, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func askAccount(t *testing.T, ref *actor.LocalRef, ask interface{}) int64 {
+	var balance int64
+	if err := ref.Ask(nil, ask, &balance); err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func shutdownAccount(t *testing.T, ref *actor.LocalRef) {
+	if err := ref.Shutdown(nil); err != nil {
+		t.Fatal(err)
+	}
+	for ref.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPersistentActor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-actor-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	journal, err := actor.NewFileJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actor.SetJournal(journal)
+	defer actor.SetJournal(actor.NewMemoryJournal())
+
+	ref := spawnAccount(t, "1")
+	askAccount(t, ref, int64(10))
+	askAccount(t, ref, int64(20))
+	if b := askAccount(t, ref, "snapshot"); b != 30 {
+		t.Fatalf("balance %d, want 30", b)
+	}
+	askAccount(t, ref, int64(-5))
+	shutdownAccount(t, ref)
+	journal.Close()
+
+	// a new journal reads the same files, as if the process has restarted
+	journal, err = actor.NewFileJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actor.SetJournal(journal)
+	defer journal.Close()
+	ref = spawnAccount(t, "1")
+	if b := askAccount(t, ref, "balance"); b != 25 {
+		t.Fatalf("recovered balance %d, want 25", b)
+	}
+	if seq := ref.PersistSeq(); seq != 3 {
+		t.Fatalf("recovered seq %d, want 3", seq)
+	}
+	if b := askAccount(t, ref, int64(1)); b != 26 {
+		t.Fatalf("balance %d, want 26", b)
+	}
+	shutdownAccount(t, ref)
+
+	other := spawnAccount(t, "2")
+	if b := askAccount(t, other, "balance"); b != 0 {
+		t.Fatalf("other balance %d, want 0", b)
+	}
+	shutdownAccount(t, other)
+}
+
+func TestFileJournalTornRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-actor-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	journal, err := actor.NewFileJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range []string{"a", "b"} {
+		if err := journal.Append("torn", uint64(i+1), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journal.Close()
+
+	// a half written record, as if the process has crashed during appending
+	f, err := os.OpenFile(filepath.Join(dir, "torn.journal"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 100, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	journal, err = actor.NewFileJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	if err := journal.Append("torn", 3, "c"); err != nil {
+		t.Fatal(err)
+	}
+	var events []interface{}
+	if err := journal.Replay("torn", 0, func(seq uint64, event interface{}) error {
+		events = append(events, event)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[0] != "a" || events[1] != "b" || events[2] != "c" {
+		t.Fatalf("replayed %v, want [a b c]", events)
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"sync"
+	"testing"
+	"time"
+)
+
+// WORKER ACTOR, handles a message slowly, and panics on "panic".
+
+type workerActor struct {
+}
+
+func (m *workerActor) Type() (name string, version int) {
+	return "worker", 1
+}
+
+func (m *workerActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *workerActor) Started() {
+}
+
+func (m *workerActor) HandleSend(sender actor.Ref, message interface{}) {
+	if message == "panic" {
+		panic("worker panic")
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+func (m *workerActor) Shutdown() {
+}
+
+func waitPoolSize(pool *actor.PoolRef, cond func(n int) bool) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond(pool.Size()) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestPoolAutoscaling(t *testing.T) {
+	pool, err := actor.SpawnPool(func() actor.Actor { return &workerActor{} }, nil, actor.PoolConfig{
+		Min:           1,
+		Max:           4,
+		Strategy:      actor.RouteSmallestMailbox,
+		ScaleInterval: 10 * time.Millisecond,
+		ScaleDownIdle: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Shutdown(nil)
+	if n := pool.Size(); n != 1 {
+		t.Fatalf("pool size %d, want 1", n)
+	}
+
+	// flood the pool
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				pool.Send(nil, j)
+			}
+		}()
+	}
+	if !waitPoolSize(pool, func(n int) bool { return n == 4 }) {
+		t.Fatalf("pool size %d, want grow to 4", pool.Size())
+	}
+	wg.Wait()
+	if !waitPoolSize(pool, func(n int) bool { return n == 1 }) {
+		t.Fatalf("pool size %d, want shrink to 1", pool.Size())
+	}
+
+	// crashed worker is replaced
+	routees := pool.Routees()
+	if len(routees) == 0 {
+		t.Fatal("pool has no routee")
+	}
+	worker := routees[0]
+	if err := pool.Send(nil, "panic"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		routees = pool.Routees()
+		if worker.Status() != actor.Running && len(routees) > 0 && routees[0] != worker {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("crashed worker is not replaced")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pool.Status() != actor.Running || pool.Size() != 1 {
+		t.Fatalf("pool size %d, want 1 running worker", pool.Size())
+	}
+}
+
+func TestPoolScaleUpLatency(t *testing.T) {
+	pool, err := actor.SpawnPool(func() actor.Actor { return &workerActor{} }, nil, actor.PoolConfig{
+		Min:            1,
+		Max:            3,
+		ScaleInterval:  10 * time.Millisecond,
+		ScaleUpMailbox: 1000,
+		ScaleUpLatency: 10 * time.Millisecond,
+		ScaleDownIdle:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Shutdown(nil)
+	for i := 0; i < 30; i++ {
+		pool.Send(nil, i)
+	}
+	if !waitPoolSize(pool, func(n int) bool { return n > 1 }) {
+		t.Fatalf("pool size %d, want grow by latency", pool.Size())
+	}
+
+	// slow workers have been idle, they are not busy any more
+	if !waitPoolSize(pool, func(n int) bool { return n == 1 }) {
+		t.Fatalf("pool size %d, want shrink to 1", pool.Size())
+	}
+}
+
+func TestPoolConfig(t *testing.T) {
+	_, err := actor.SpawnPool(func() actor.Actor { return &workerActor{} }, nil, actor.PoolConfig{Min: 2, Max: 1})
+	if err != actor.ErrArgument {
+		t.Fatal("invalid config should fail,", err)
+	}
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"sync/atomic"
+	"testing"
+)
+
+// COUNTER ACTOR
+
+type counterActor struct {
+	count int64
+}
+
+func (m *counterActor) Type() (name string, version int) {
+	return "counter", 1
+}
+
+func (m *counterActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *counterActor) Started() {
+}
+
+func (m *counterActor) HandleSend(sender actor.Ref, message interface{}) {
+	atomic.AddInt64(&m.count, 1)
+}
+
+func (m *counterActor) Shutdown() {
+}
+
+// Many goroutines send to the same remote actor, they share one node connection.
+func BenchmarkRemoteSendParallel(b *testing.B) {
+	initRemote(b)
+	counter := &counterActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return counter }, "bench_counter", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		b.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("bench_counter")
+	if err != nil {
+		b.Fatal("by name error,", err)
+	}
+
+	b.SetParallelism(64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ref.Send(nil, "bench"); err != nil {
+				b.Error("send error,", err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+// Fire-and-forget sending does not wait for a round trip per message.
+func BenchmarkRemoteSendNoAckParallel(b *testing.B) {
+	initRemote(b)
+	counter := &counterActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return counter }, "bench_no_ack_counter", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		b.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("bench_no_ack_counter")
+	if err != nil {
+		b.Fatal("by name error,", err)
+	}
+	ref.SetAckMode(actor.SendAckNone)
+
+	b.SetParallelism(64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ref.Send(nil, "bench"); err != nil {
+				b.Error("send error,", err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msgs/s")
+}
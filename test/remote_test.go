@@ -0,0 +1,525 @@
+package test
+
+import (
+	"encoding/binary"
+	"github.com/golang/protobuf/proto"
+	"github.com/hwangtou/go-actor"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testNodeId      = 1
+	testNodeAddress = "127.0.0.1:12399"
+)
+
+var remoteOnce sync.Once
+
+// All tests share the default actor system, so the remote node is initialized once.
+func initRemote(t testing.TB) {
+	remoteOnce.Do(func() {
+		if err := actor.Remote.Init(actor.NodeConfig{
+			Id:                testNodeId,
+			ListenAddress:     testNodeAddress,
+			HeartbeatInterval: 200 * time.Millisecond,
+			HeartbeatMissed:   5,
+		}); err != nil {
+			t.Fatal("remote init error,", err)
+		}
+	})
+}
+
+// SILENT NODE
+// It answers authentication and name lookup, then stops answering anything,
+// just like a node which has crashed with a half-open tcp connection.
+
+func readPacket(conn net.Conn) (*actor.ConnMessage, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	size, _ := binary.Varint(header)
+	body := make([]byte, size)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+	p := &actor.ConnMessage{}
+	return p, proto.Unmarshal(body, p)
+}
+
+func writePacket(conn net.Conn, p *actor.ConnMessage) error {
+	buf, err := proto.Marshal(p)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 4)
+	binary.PutVarint(header, int64(len(buf)))
+	_, err = conn.Write(append(header, buf...))
+	return err
+}
+
+func silentNode(t *testing.T, addr string) net.Listener {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		for {
+			p, err := readPacket(conn)
+			if err != nil {
+				return
+			}
+			switch p.Type {
+			case actor.ControlType_CAuth:
+				err = writePacket(conn, &actor.ConnMessage{
+					SequenceId: p.SequenceId,
+					Type:       actor.ControlType_CAuth,
+					Direction:  actor.Direction_Response,
+					Content: &actor.ConnMessage_Auth{Auth: &actor.Auth{
+						Data: &actor.Auth_Resp{Resp: &actor.Auth_Response{IsAuth: true}},
+					}},
+				})
+			case actor.ControlType_CGetName:
+				err = writePacket(conn, &actor.ConnMessage{
+					SequenceId: p.SequenceId,
+					Type:       actor.ControlType_CGetName,
+					Direction:  actor.Direction_Response,
+					Content: &actor.ConnMessage_GetName{GetName: &actor.GetName{
+						Data: &actor.GetName_Resp{Resp: &actor.GetName_Response{Has: true, ActorId: 1}},
+					}},
+				})
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return l
+}
+
+func TestRemoteNodeDown(t *testing.T) {
+	initRemote(t)
+	l := silentNode(t, "127.0.0.1:12398")
+	defer l.Close()
+
+	downCh := make(chan uint32, 1)
+	actor.Remote.OnNodeDown(func(nodeId uint32) {
+		if nodeId == 99 {
+			downCh <- nodeId
+		}
+	})
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            99,
+		ListenNetwork: actor.TCP,
+		ListenAddress: "127.0.0.1:12398",
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("silent")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	if ref.Status() != actor.Running {
+		t.Fatal("remote ref should be running")
+	}
+
+	beginAt := time.Now()
+	err = ref.Send(nil, "hello")
+	log.Println("send to silent node,", err, time.Since(beginAt))
+	if err != actor.ErrRemoteNodeDown {
+		t.Fatal("expect node down error, got", err)
+	}
+	if time.Since(beginAt) >= 5*time.Second {
+		t.Fatal("node down should be detected before request timeout")
+	}
+	select {
+	case <-downCh:
+	case <-time.After(time.Second):
+		t.Fatal("node down event not received")
+	}
+	if ref.Status() != actor.Halt {
+		t.Fatal("remote ref should be halt")
+	}
+	if _, err := actor.Remote.GetConn(99); err != actor.ErrRemoteConnNotFound {
+		t.Fatal("down node should be removed,", err)
+	}
+}
+
+func TestRemoteHeartbeat(t *testing.T) {
+	initRemote(t)
+	if _, err := actor.SpawnWithName(func() actor.Actor { return &simpleActor{} }, "heartbeat", nil); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("heartbeat")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	// Idle connection should be kept alive by heartbeats.
+	<-time.After(500 * time.Millisecond)
+	if ref.Status() != actor.Running {
+		t.Fatal("remote ref should be running")
+	}
+	if err := ref.Send(nil, "hello"); err != nil {
+		t.Fatal("send error,", err)
+	}
+	actor.ByName("heartbeat").Shutdown(nil)
+}
+
+func TestRemoteSendAckModes(t *testing.T) {
+	initRemote(t)
+	counter := &counterActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return counter }, "ack_counter", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("ack_counter")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+
+	// per call
+	for i := 0; i < 100; i++ {
+		if err := ref.SendWithAckMode(nil, "no ack", actor.SendAckNone); err != nil {
+			t.Fatal("send no ack error,", err)
+		}
+	}
+	// per reference
+	ref.SetAckMode(actor.SendAckBatch)
+	for i := 0; i < 100; i++ {
+		if err := ref.Send(nil, "batch ack"); err != nil {
+			t.Fatal("send batch ack error,", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&counter.count) < 200 {
+		if time.Now().After(deadline) {
+			t.Fatal("messages not delivered,", atomic.LoadInt64(&counter.count))
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+}
+
+// RECORDER ACTOR
+
+type recorderActor struct {
+	sync.Mutex
+	messages []interface{}
+}
+
+func (m *recorderActor) Type() (name string, version int) {
+	return "recorder", 1
+}
+
+func (m *recorderActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *recorderActor) Started() {
+}
+
+func (m *recorderActor) HandleSend(sender actor.Ref, message interface{}) {
+	m.Lock()
+	m.messages = append(m.messages, message)
+	m.Unlock()
+}
+
+func (m *recorderActor) Shutdown() {
+}
+
+func (m *recorderActor) received() []interface{} {
+	m.Lock()
+	defer m.Unlock()
+	return append([]interface{}{}, m.messages...)
+}
+
+func TestRemoteSendOrdered(t *testing.T) {
+	initRemote(t)
+	recorder := &recorderActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "ordered_recorder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	ref, err := conn.ByName("ordered_recorder")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	ref.SetAckMode(actor.SendAckNone)
+	const count = 500
+	for i := 0; i < count; i++ {
+		if err := ref.Send(lr, strconv.Itoa(i)); err != nil {
+			t.Fatal("send error,", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.received()) < count {
+		if time.Now().After(deadline) {
+			t.Fatal("messages not delivered,", len(recorder.received()))
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	for i, msg := range recorder.received() {
+		if msg != strconv.Itoa(i) {
+			t.Fatal("message out of order, expect", i, "got", msg)
+		}
+	}
+}
+
+func TestRemoteSendReliable(t *testing.T) {
+	initRemote(t)
+	dir, err := ioutil.TempDir("", "reliable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := actor.NewFileReliableStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := actor.Remote.EnableReliable(actor.ReliableConfig{
+		Store:         store,
+		RetryInterval: 100 * time.Millisecond,
+	}); err != nil {
+		t.Fatal("enable reliable error,", err)
+	}
+	defer actor.Remote.DisableReliable()
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	recorder := &recorderActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "reliable_recorder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := conn.ByName("reliable_recorder")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	if err := ref.SendReliable(nil, "first", actor.DeliveryExactlyOnce); err != nil {
+		t.Fatal("send reliable error,", err)
+	}
+
+	// The receiver is shutting down, the message is kept and retried until
+	// the receiver comes back.
+	lr.Shutdown(nil)
+	<-time.After(10 * time.Millisecond)
+	if err := ref.SendReliable(nil, "second", actor.DeliveryExactlyOnce); err != actor.ErrRemotePending {
+		t.Fatal("expect pending error, got", err)
+	}
+	if records, _ := store.Load(); len(records) != 1 {
+		t.Fatal("pending message should be persisted,", len(records))
+	}
+	recorder = &recorderActor{}
+	if _, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "reliable_recorder", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer actor.ByName("reliable_recorder").Shutdown(nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.received()) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("pending message not retried")
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	<-time.After(300 * time.Millisecond)
+	if received := recorder.received(); len(received) != 1 || received[0] != "second" {
+		t.Fatal("pending message should be delivered once,", received)
+	}
+	if records, _ := store.Load(); len(records) != 0 {
+		t.Fatal("acknowledged message should be deleted,", len(records))
+	}
+}
+
+func TestRemoteReliableRecovery(t *testing.T) {
+	initRemote(t)
+	dir, err := ioutil.TempDir("", "reliable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := actor.NewFileReliableStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A message left by last running process.
+	req, err := proto.Marshal(&actor.SendName_Request{
+		ToName: "recovery_recorder",
+		SendData: &actor.DataContentType{
+			Type:    actor.DataType_String,
+			Content: &actor.DataContentType_Str{Str: "left"},
+		},
+		Delivery:  actor.DeliveryExactlyOnce,
+		MessageId: "recovery-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&actor.ReliableRecord{
+		Id:        "recovery-1",
+		NodeId:    testNodeId,
+		Request:   req,
+		CreatedAt: time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &recorderActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "recovery_recorder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	if _, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	}); err != nil {
+		t.Fatal("dial error,", err)
+	}
+	if err := actor.Remote.EnableReliable(actor.ReliableConfig{
+		Store:         store,
+		RetryInterval: 100 * time.Millisecond,
+	}); err != nil {
+		t.Fatal("enable reliable error,", err)
+	}
+	defer actor.Remote.DisableReliable()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.received()) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("stored message not delivered")
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	if received := recorder.received(); received[0] != "left" {
+		t.Fatal("unexpected message,", received)
+	}
+	deadline = time.Now().Add(time.Second)
+	for {
+		records, _ := store.Load()
+		if len(records) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("acknowledged message should be deleted,", len(records))
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+}
+
+func TestRemoteReliableExpired(t *testing.T) {
+	initRemote(t)
+	store := actor.NewMemoryReliableStore()
+
+	// A message sent before the deduplication window.
+	req, err := proto.Marshal(&actor.SendName_Request{
+		ToName: "expired_recorder",
+		SendData: &actor.DataContentType{
+			Type:    actor.DataType_String,
+			Content: &actor.DataContentType_Str{Str: "expired"},
+		},
+		Delivery:  actor.DeliveryExactlyOnce,
+		MessageId: "expired-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&actor.ReliableRecord{
+		Id:        "expired-1",
+		NodeId:    testNodeId,
+		Request:   req,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &recorderActor{}
+	lr, err := actor.SpawnWithName(func() actor.Actor { return recorder }, "expired_recorder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lr.Shutdown(nil)
+	deadLetters := &recorderActor{}
+	dl, err := actor.Spawn(func() actor.Actor { return deadLetters }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dl.Shutdown(nil)
+	if err := actor.Events.SubscribeType(dl, &actor.DeadLetter{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	}); err != nil {
+		t.Fatal("dial error,", err)
+	}
+	if err := actor.Remote.EnableReliable(actor.ReliableConfig{
+		Store:         store,
+		RetryInterval: 100 * time.Millisecond,
+	}); err != nil {
+		t.Fatal("enable reliable error,", err)
+	}
+	defer actor.Remote.DisableReliable()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(deadLetters.received()) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired message should be a dead letter")
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	letter, ok := deadLetters.received()[0].(*actor.DeadLetter)
+	if !ok || letter.Reason != actor.ErrReliableExpired || letter.Message != "expired" {
+		t.Fatal("unexpected dead letter,", deadLetters.received())
+	}
+	if records, _ := store.Load(); len(records) != 0 {
+		t.Fatal("expired message should be deleted,", len(records))
+	}
+	if received := recorder.received(); len(received) != 0 {
+		t.Fatal("expired message should not be retried,", received)
+	}
+}
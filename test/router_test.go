@@ -0,0 +1,142 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+func spawnRecorders(t *testing.T, n int) ([]*recorderActor, []actor.Ref) {
+	var recorders []*recorderActor
+	var refs []actor.Ref
+	for i := 0; i < n; i++ {
+		recorder := &recorderActor{}
+		ref, err := actor.Spawn(func() actor.Actor { return recorder }, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorders = append(recorders, recorder)
+		refs = append(refs, ref)
+	}
+	return recorders, refs
+}
+
+func waitReceived(recorders []*recorderActor, total int) int {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		count := 0
+		for _, r := range recorders {
+			count += len(r.received())
+		}
+		if count >= total || time.Now().After(deadline) {
+			return count
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type hashMessage string
+
+func (m hashMessage) HashKey() string {
+	return string(m)
+}
+
+func TestRouterRoundRobin(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 3)
+	router := actor.NewRouter(actor.RouteRoundRobin, refs...)
+	defer router.Shutdown(nil)
+	for i := 0; i < 30; i++ {
+		if err := router.Send(nil, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitReceived(recorders, 30)
+	for i, r := range recorders {
+		if n := len(r.received()); n != 10 {
+			t.Fatalf("routee %d received %d, want 10", i, n)
+		}
+	}
+
+	// halted routee is skipped
+	refs[0].Shutdown(nil)
+	for refs[0].Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		if err := router.Send(nil, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := waitReceived(recorders[1:], 30); n != 30 {
+		t.Fatalf("running routees received %d, want 30", n)
+	}
+}
+
+func TestRouterConsistentHash(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 4)
+	router := actor.NewRouter(actor.RouteConsistentHash, refs...)
+	defer router.Shutdown(nil)
+	for i := 0; i < 20; i++ {
+		if err := router.Send(nil, hashMessage("order-42")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitReceived(recorders, 20)
+	for _, r := range recorders {
+		if n := len(r.received()); n != 0 && n != 20 {
+			t.Fatalf("messages of a key are split, routee received %d", n)
+		}
+	}
+	if err := router.Send(nil, 42); err != actor.ErrRouterHashKey {
+		t.Fatal("message without key should fail,", err)
+	}
+}
+
+func TestRouterBroadcast(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 3)
+	router := actor.NewRouter(actor.RouteBroadcast, refs...)
+	if err := router.Send(nil, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitReceived(recorders, 3); n != 3 {
+		t.Fatalf("received %d, want 3", n)
+	}
+	var answer string
+	if err := router.Ask(nil, "hello", &answer); err != actor.ErrRouterBroadcastAsk {
+		t.Fatal("broadcast ask should fail,", err)
+	}
+	router.Shutdown(nil)
+	for router.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	if err := router.Send(nil, "hello"); err != actor.ErrRouterNoRoutee {
+		t.Fatal("router without running routee should fail,", err)
+	}
+}
+
+func TestRouterSmallestMailbox(t *testing.T) {
+	blocking := &blockingActor{handled: make(chan int, 10)}
+	busy, err := actor.Spawn(func() actor.Actor { return blocking }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// busy routee blocks forever on message 2, with senders waiting
+	busy.Send(nil, 2)
+	for i := 0; i < 10; i++ {
+		go busy.Send(nil, 3)
+	}
+	for busy.MailboxSize() < 11 {
+		time.Sleep(time.Millisecond)
+	}
+	recorders, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	router := actor.NewRouter(actor.RouteSmallestMailbox, busy, refs[0])
+	for i := 0; i < 10; i++ {
+		if err := router.Send(nil, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := waitReceived(recorders, 10); n != 10 {
+		t.Fatalf("idle routee received %d, want 10", n)
+	}
+}
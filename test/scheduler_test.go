@@ -0,0 +1,87 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSendAfter(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	begin := time.Now()
+	actor.SendAfter(50*time.Millisecond, refs[0], "later")
+	canceled := actor.SendAfter(50*time.Millisecond, refs[0], "canceled")
+	if !canceled.Cancel() || !canceled.IsCanceled() {
+		t.Fatal("cancel should succeed")
+	}
+	if n := waitReceived(recorders, 1); n != 1 {
+		t.Fatalf("received %d, want 1", n)
+	}
+	if d := time.Since(begin); d < 50*time.Millisecond {
+		t.Fatalf("sent after %v, want 50ms", d)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := recorders[0].received(); len(got) != 1 || got[0] != "later" {
+		t.Fatalf("received %v", got)
+	}
+}
+
+func TestSendAfterCascade(t *testing.T) {
+	t.Parallel()
+	// longer than the first level of wheel
+	const delay = 2700 * time.Millisecond
+	recorders, refs := spawnRecorders(t, 1)
+	defer refs[0].Shutdown(nil)
+	begin := time.Now()
+	actor.SendAfter(delay, refs[0], "cascaded")
+	for len(recorders[0].received()) == 0 {
+		if time.Since(begin) > 2*delay {
+			t.Fatal("cascaded message is not sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if d := time.Since(begin); d < delay || d > delay+200*time.Millisecond {
+		t.Fatalf("sent after %v, want %v", d, delay)
+	}
+}
+
+func TestSendEvery(t *testing.T) {
+	recorders, refs := spawnRecorders(t, 2)
+	owner, target := refs[0], refs[1]
+	defer target.Shutdown(nil)
+	tick := owner.(*actor.LocalRef).SendEvery(20*time.Millisecond, target, "tick")
+	if n := waitReceived(recorders[1:], 3); n < 3 {
+		t.Fatalf("received %d, want at least 3", n)
+	}
+	// periodic message is canceled once its owner has halt
+	owner.Shutdown(nil)
+	for owner.Status() != actor.Halt {
+		time.Sleep(time.Millisecond)
+	}
+	if !tick.IsCanceled() {
+		t.Fatal("periodic message should be canceled with its owner")
+	}
+	n := len(recorders[1].received())
+	time.Sleep(60 * time.Millisecond)
+	if m := len(recorders[1].received()); m > n+1 {
+		t.Fatalf("received %d after canceled, want at most %d", m, n+1)
+	}
+}
+
+func TestSendEveryBlockedTarget(t *testing.T) {
+	blocking := &blockingActor{handled: make(chan int, 10)}
+	busy, err := actor.Spawn(func() actor.Actor { return blocking }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// busy target blocks forever on message 2, ticks are skipped instead of piling up
+	before := runtime.NumGoroutine()
+	tick := actor.SendEvery(10*time.Millisecond, busy, 2)
+	defer tick.Cancel()
+	time.Sleep(300 * time.Millisecond)
+	if n := runtime.NumGoroutine() - before; n > 5 {
+		t.Fatalf("%d goroutines are sending to blocked target", n)
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+// STASH ACTOR, defers messages until it is ready.
+
+type stashActor struct {
+	recorderActor
+	self      *actor.LocalRef
+	ready     bool
+	stashErrs []error
+}
+
+func (m *stashActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	m.self = self
+	return nil
+}
+
+func (m *stashActor) HandleSend(sender actor.Ref, message interface{}) {
+	if message == "ready" {
+		m.ready = true
+		m.self.UnstashAll()
+		return
+	}
+	if !m.ready {
+		if err := m.self.Stash(); err != nil {
+			m.Lock()
+			m.stashErrs = append(m.stashErrs, err)
+			m.Unlock()
+		}
+		return
+	}
+	m.recorderActor.HandleSend(sender, message)
+}
+
+func (m *stashActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	if !m.ready {
+		return nil, m.self.Stash()
+	}
+	return len(m.received()), nil
+}
+
+func TestStash(t *testing.T) {
+	a := &stashActor{}
+	ref, err := actor.Spawn(func() actor.Actor { return a }, nil, actor.WithStashCapacity(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	if err := a.self.Stash(); err != actor.ErrStashNoMessage {
+		t.Fatal("stash outside handler should fail,", err)
+	}
+
+	answerCh := make(chan int)
+	go func() {
+		var n int
+		if err := ref.Ask(nil, "count", &n); err != nil {
+			t.Error(err)
+		}
+		answerCh <- n
+	}()
+	for a.self.StashSize() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, msg := range []string{"first", "second", "overflow"} {
+		if err := ref.Send(nil, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-answerCh:
+		t.Fatal("stashed ask should not be answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	a.Lock()
+	if len(a.stashErrs) != 1 || a.stashErrs[0] != actor.ErrStashFull {
+		t.Fatalf("stash errors %v, want ErrStashFull", a.stashErrs)
+	}
+	a.Unlock()
+
+	// unstashed messages are handled in original order, before later messages
+	if err := ref.Send(nil, "ready"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ref.Send(nil, "third"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-answerCh:
+		if n != 0 {
+			t.Fatalf("answered %d, want 0", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("unstashed ask should be answered")
+	}
+	if n := waitReceived([]*recorderActor{&a.recorderActor}, 3); n != 3 {
+		t.Fatalf("received %d, want 3", n)
+	}
+	got := a.received()
+	if got[0] != "first" || got[1] != "second" || got[2] != "third" {
+		t.Fatalf("received %v", got)
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+	"time"
+)
+
+// PANIC ACTOR, panics on "boom", and counts other asks.
+
+type panicActor struct {
+	recorderActor
+	count int
+}
+
+func (m *panicActor) HandleAsk(sender actor.Ref, ask interface{}) (answer interface{}, err error) {
+	if ask == "boom" {
+		panic("boom")
+	}
+	m.count++
+	return m.count, nil
+}
+
+func spawnPanicActor(t *testing.T, opts ...actor.SpawnOption) actor.Ref {
+	ref, err := actor.Spawn(func() actor.Actor { return &panicActor{} }, nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func askCount(t *testing.T, ref actor.Ref) int {
+	var n int
+	if err := ref.Ask(nil, "count", &n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func askBoom(t *testing.T, ref actor.Ref) {
+	var n int
+	err := ref.Ask(nil, "boom", &n)
+	perr, ok := err.(*actor.PanicError)
+	if !ok || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Fatal("ask should receive the panic,", err)
+	}
+}
+
+func TestPanicStop(t *testing.T) {
+	ref := spawnPanicActor(t)
+	askBoom(t, ref)
+	deadline := time.Now().Add(time.Second)
+	for ref.Status() != actor.Halt {
+		if time.Now().After(deadline) {
+			t.Fatal("panicked actor should be stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var n int
+	if err := ref.Ask(nil, "count", &n); err != actor.ErrActorNotRunning {
+		t.Fatal("ask to stopped actor should fail,", err)
+	}
+}
+
+func TestPanicResume(t *testing.T) {
+	ref := spawnPanicActor(t, actor.WithPanicPolicy(func(self *actor.LocalRef, err *actor.PanicError) actor.PanicDirective {
+		return actor.PanicResume
+	}))
+	defer ref.Shutdown(nil)
+	askCount(t, ref)
+	askBoom(t, ref)
+	if n := askCount(t, ref); n != 2 {
+		t.Fatalf("count %d, want 2, state should be kept", n)
+	}
+	if ref.Status() != actor.Running {
+		t.Fatal("resumed actor should be running")
+	}
+}
+
+func TestPanicRestart(t *testing.T) {
+	restarts := 0
+	ref := spawnPanicActor(t, actor.WithPanicPolicy(func(self *actor.LocalRef, err *actor.PanicError) actor.PanicDirective {
+		restarts++
+		return actor.PanicRestart
+	}))
+	defer ref.Shutdown(nil)
+	askCount(t, ref)
+	askCount(t, ref)
+	askBoom(t, ref)
+	if n := askCount(t, ref); n != 1 {
+		t.Fatalf("count %d, want 1, state should be reset", n)
+	}
+	if restarts != 1 || ref.Status() != actor.Running {
+		t.Fatal("restarted actor should be running")
+	}
+}
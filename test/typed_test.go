@@ -0,0 +1,94 @@
+package test
+
+import (
+	"github.com/hwangtou/go-actor"
+	"testing"
+)
+
+// TYPED COUNTER ACTOR, a typed actor.
+
+type typedCounterActor struct {
+	count int
+}
+
+func (m *typedCounterActor) Type() (name string, version int) {
+	return "counter", 1
+}
+
+func (m *typedCounterActor) StartUp(self *actor.LocalRef, arg interface{}) error {
+	return nil
+}
+
+func (m *typedCounterActor) Started() {
+}
+
+func (m *typedCounterActor) HandleSend(sender actor.Ref, message int) {
+	m.count += message
+}
+
+func (m *typedCounterActor) HandleAsk(sender actor.Ref, ask int) (answer int, err error) {
+	m.count += ask
+	return m.count, nil
+}
+
+func (m *typedCounterActor) Shutdown() {
+}
+
+func TestTypedRef(t *testing.T) {
+	ref, err := actor.SpawnTyped(func() actor.TypedActor[int, int] { return &typedCounterActor{} }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Shutdown(nil)
+	if err := ref.Send(nil, 2); err != nil {
+		t.Fatal(err)
+	}
+	n, err := ref.Ask(nil, 3)
+	if err != nil || n != 5 {
+		t.Fatalf("answered %d %v, want 5", n, err)
+	}
+
+	// untyped reference
+	var answer int
+	if err := ref.Ref().Ask(nil, "3", &answer); err != actor.ErrMessageType {
+		t.Fatal("ask of wrong type should fail,", err)
+	}
+	typed := actor.NewTypedRef[int, int](ref.Ref())
+	if n, err := typed.Ask(nil, 0); err != nil || n != 5 {
+		t.Fatalf("answered %d %v, want 5", n, err)
+	}
+}
+
+func TestTypedRefRemote(t *testing.T) {
+	initRemote(t)
+	local, err := actor.SpawnTypedWithName(func() actor.TypedActor[int, int] { return &typedCounterActor{} }, "typed_counter", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Shutdown(nil)
+	conn, err := actor.Remote.Dial(actor.NodeConfig{
+		Id:            testNodeId,
+		ListenNetwork: actor.TCP,
+		ListenAddress: testNodeAddress,
+	})
+	if err != nil {
+		t.Fatal("dial error,", err)
+	}
+	remoteRef, err := conn.ByName("typed_counter")
+	if err != nil {
+		t.Fatal("by name error,", err)
+	}
+	ref := actor.NewTypedRef[int, int](remoteRef)
+	if n, err := ref.Ask(nil, 3); err != nil || n != 3 {
+		t.Fatalf("answered %d %v, want 3", n, err)
+	}
+	if n, err := ref.Ask(nil, 4); err != nil || n != 7 {
+		t.Fatalf("answered %d %v, want 7", n, err)
+	}
+
+	// answer of another type is not assigned
+	var answer string
+	if err := remoteRef.Ask(nil, 1, &answer); err == nil {
+		t.Fatal("ask with answer of wrong type should fail")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Tou.Hwang. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package actor
+
+import "reflect"
+
+// Actor with typed messages, Req is the type of both sent and asked messages, and
+// Resp is the type of answers.
+type TypedActor[Req, Resp any] interface {
+	Type() (name string, version int)
+	StartUp(self *LocalRef, arg interface{}) error
+	Started()
+	Shutdown()
+	HandleSend(sender Ref, message Req)
+	HandleAsk(sender Ref, ask Req) (answer Resp, err error)
+}
+
+// Spawn a typed actor, returns its typed reference.
+func SpawnTyped[Req, Resp any](fn func() TypedActor[Req, Resp], arg interface{}, opts ...SpawnOption) (TypedRef[Req, Resp], error) {
+	return SpawnTypedWithName(fn, "", arg, opts...)
+}
+
+// Spawn a typed actor with name, returns its typed reference.
+func SpawnTypedWithName[Req, Resp any](fn func() TypedActor[Req, Resp], name string, arg interface{}, opts ...SpawnOption) (TypedRef[Req, Resp], error) {
+	lr, err := defaultSys.SpawnWithName(func() Actor {
+		return &typedActor[Req, Resp]{typed: fn()}
+	}, name, arg, opts...)
+	if err != nil {
+		return TypedRef[Req, Resp]{}, err
+	}
+	return NewTypedRef[Req, Resp](lr), nil
+}
+
+// Adapter of typed actor, messages of other types are dead letters, and asks of
+// other types are answered with ErrMessageType.
+type typedActor[Req, Resp any] struct {
+	typed TypedActor[Req, Resp]
+	self  *LocalRef
+}
+
+func (m *typedActor[Req, Resp]) Type() (name string, version int) {
+	return m.typed.Type()
+}
+
+func (m *typedActor[Req, Resp]) StartUp(self *LocalRef, arg interface{}) error {
+	m.self = self
+	return m.typed.StartUp(self, arg)
+}
+
+func (m *typedActor[Req, Resp]) Started() {
+	m.typed.Started()
+}
+
+func (m *typedActor[Req, Resp]) HandleSend(sender Ref, message interface{}) {
+	req, ok := message.(Req)
+	if !ok {
+		m.self.local.sys.deadLetter(sender, m.self.id, message, ErrMessageType)
+		return
+	}
+	m.typed.HandleSend(sender, req)
+}
+
+func (m *typedActor[Req, Resp]) HandleAsk(sender Ref, ask interface{}) (answer interface{}, err error) {
+	req, ok := ask.(Req)
+	if !ok {
+		return nil, ErrMessageType
+	}
+	return m.typed.HandleAsk(sender, req)
+}
+
+func (m *typedActor[Req, Resp]) Shutdown() {
+	m.typed.Shutdown()
+}
+
+//
+// Typed Reference
+//
+
+// Typed wrapper of a Ref, no matter LocalRef or RemoteRef, types of messages and
+// answers are checked at compile time.
+type TypedRef[Req, Resp any] struct {
+	ref Ref
+}
+
+func NewTypedRef[Req, Resp any](ref Ref) TypedRef[Req, Resp] {
+	return TypedRef[Req, Resp]{ref: ref}
+}
+
+// Untyped reference.
+func (m TypedRef[Req, Resp]) Ref() Ref {
+	return m.ref
+}
+
+func (m TypedRef[Req, Resp]) Id() Id {
+	return m.ref.Id()
+}
+
+func (m TypedRef[Req, Resp]) Status() Status {
+	return m.ref.Status()
+}
+
+func (m TypedRef[Req, Resp]) Send(sender Ref, msg Req) error {
+	return m.ref.Send(sender, msg)
+}
+
+func (m TypedRef[Req, Resp]) Ask(sender Ref, ask Req) (Resp, error) {
+	var answer Resp
+	// remote answer of pointer type, such as ProtoBuf message, is unmarshalled to
+	// a new object
+	if t := reflect.TypeOf(answer); t != nil && t.Kind() == reflect.Ptr {
+		answer = reflect.New(t.Elem()).Interface().(Resp)
+	}
+	err := m.ref.Ask(sender, ask, &answer)
+	return answer, err
+}
+
+func (m TypedRef[Req, Resp]) Shutdown(sender Ref) error {
+	return m.ref.Shutdown(sender)
+}